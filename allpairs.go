@@ -0,0 +1,79 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// PairDistances maps pairs of states {from, to} to the cost of the shortest
+// path between them, as returned by AllPairs. Pairs without a path are absent.
+type PairDistances map[[2]State]float64
+
+// Dist returns the cost of the shortest path from one state to another, or
+// +Inf if there is none.
+func (p PairDistances) Dist(from, to State) float64 {
+	if c, ok := p[[2]State{from, to}]; ok {
+		return c
+	}
+	return math.Inf(1)
+}
+
+// Path extracts the shortest path from one state to another using the
+// precomputed distances, or returns nil if there is none. The data must be the
+// same as the one passed to AllPairs.
+//
+// States already on the path are never revisited, such that zero-cost cycles
+// cannot make Path loop forever; if no other successor is left (e.g. because
+// the data has changed since AllPairs), Path returns nil.
+func (p PairDistances) Path(d Data, from, to State) []State {
+	if math.IsInf(p.Dist(from, to), 1) {
+		return nil
+	}
+	path := []State{from}
+	visited := map[State]bool{from: true}
+	for st := from; !st.Equals(to); {
+		var next State
+		best := math.Inf(1)
+		for _, sPrime := range d.Succ(st) {
+			if visited[sPrime] {
+				continue
+			}
+			c := d.Cost(st, sPrime) + p.Dist(sPrime, to)
+			if c < best {
+				best = c
+				next = sPrime
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		visited[next] = true
+		st = next
+		path = append(path, st)
+	}
+	return path
+}
+
+// AllPairs computes the cost of the shortest path between every pair of the
+// given states, by fully precomputing a planner towards each of them in turn.
+//
+// This takes O(V * (V log V)) time for V states (and O(V^2) memory), so it is
+// only suited to small graphs; there, when many queries are made, looking
+// the answers up can beat repeated incremental planning.
+func AllPairs(d Data, states []State) PairDistances {
+	dist := make(PairDistances)
+	for _, goal := range states {
+		p := New(d, goal, goal)
+		p.Precompute()
+		for _, from := range states {
+			if c := p.g.get(from); !math.IsInf(c, 1) {
+				dist[[2]State{from, goal}] = c
+			}
+		}
+		dist[[2]State{goal, goal}] = 0
+	}
+	return dist
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// pathCost returns the cost of the path through d, or +Inf for a nil path.
+func pathCost(d dstarlite.Data, path []dstarlite.State) float64 {
+	if path == nil {
+		return math.Inf(1)
+	}
+	var cost float64
+	for i := 1; i < len(path); i++ {
+		cost += d.Cost(path[i-1], path[i])
+	}
+	return cost
+}
+
+func TestAllPairs(t *testing.T) {
+	// A directed graph with a zero-cost cycle between c and d, and a state f
+	// that cannot be reached from any other.
+	g := graph{
+		{"a", "b"}: 1, {"b", "c"}: 2, {"a", "c"}: 4,
+		{"c", "d"}: 0, {"d", "c"}: 0,
+		{"d", "e"}: 3, {"e", "a"}: 1, {"f", "a"}: 1,
+	}
+	states := g.states()
+	dist := dstarlite.AllPairs(g, states)
+	for _, from := range states {
+		for _, to := range states {
+			want := pathCost(g, dstarlite.New(g, from, to).Plan())
+			if from == to {
+				want = 0
+			}
+			if got := dist.Dist(from, to); !costsEqual(got, want) {
+				t.Errorf("Dist(%v, %v) = %v, want %v", from, to, got, want)
+			}
+			path := dist.Path(g, from, to)
+			if got := pathCost(g, path); !costsEqual(got, want) {
+				t.Errorf("Path(%v, %v) = %v with cost %v, want cost %v", from, to, path, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// avoidData wraps a Data interface such that avoided states are impassable.
+type avoidData[S comparable] struct {
+	DataOf[S]
+	avoid func(S) bool
+}
+
+func (a avoidData[S]) Cost(u, v S) float64 {
+	if a.avoid(u) || a.avoid(v) {
+		return math.Inf(1)
+	}
+	return a.DataOf.Cost(u, v)
+}
+
+// fresh returns a new planner with the same start, goals (and their weights)
+// and options as this one, but planning through the given data from scratch.
+func (s *PlannerOf[S]) fresh(data DataOf[S]) *PlannerOf[S] {
+	p := newPlanner(data, s.start, func(p *PlannerOf[S]) {
+		p.options = s.options
+	})
+	p.goal = s.goal
+	for g, rhs := range s.goals {
+		p.addGoal(g, rhs)
+	}
+	p.blend = s.blend
+	return p
+}
+
+// PlanAvoiding returns a path like Plan does, except that the states for
+// which avoid returns true are treated as impassable for this single query.
+//
+// Since the avoided states can't be enumerated the query is solved from
+// scratch, using a temporary planner. The planner itself is not modified, such
+// that a subsequent call to Plan may again route through the avoided states.
+func (s *PlannerOf[S]) PlanAvoiding(avoid func(S) bool) []S {
+	return s.fresh(avoidData[S]{s.d, avoid}).Plan()
+}
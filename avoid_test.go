@@ -0,0 +1,77 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestPlanAvoiding(t *testing.T) {
+	// The short corridor is the top row, the long way round the bottom.
+	g, start, goal := parseGrid(`
+S.....G
+.#####.
+.#####.
+.......
+`)
+	corridor := func(s dstarlite.State) bool {
+		c := s.(grid.Coord)
+		return c.Y == 0 && c.X > 0 && c.X < 6
+	}
+	uses := func(path []dstarlite.State) bool {
+		for _, s := range path {
+			if corridor(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	p := dstarlite.New(g, start, goal)
+	if path := p.Plan(); len(path) != 7 || !uses(path) {
+		t.Fatalf("got path %v, want the corridor", path)
+	}
+	if path := p.PlanAvoiding(corridor); len(path) != 13 || uses(path) {
+		t.Errorf("PlanAvoiding returned %v, want the long way round", path)
+	}
+	if path := p.Plan(); len(path) != 7 || !uses(path) {
+		t.Errorf("Plan after PlanAvoiding returned %v, want the corridor", path)
+	}
+	if path := p.PlanAvoiding(func(s dstarlite.State) bool { return s.Equals(goal) }); path != nil {
+		t.Errorf("PlanAvoiding the goal returned %v, want nil", path)
+	}
+}
+
+func TestPlanAvoidingKeepsOptions(t *testing.T) {
+	g, start, goal := parseGrid(`
+S.....G
+.#####.
+.#####.
+.......
+`)
+	corridor := func(s dstarlite.State) bool {
+		c := s.(grid.Coord)
+		return c.Y == 0 && c.X > 0 && c.X < 6
+	}
+	tests := []struct {
+		name string
+		p    *dstarlite.Planner
+		ok   bool // Whether PlanAvoiding finds a path.
+	}{
+		{"default", dstarlite.New(g, start, goal), true},
+		{"expansion limit", dstarlite.New(g, start, goal), false},
+		{"node limit", dstarlite.New(g, start, goal), false},
+	}
+	tests[1].p.SetMaxExpansions(3)
+	tests[2].p.SetMaxNodes(3)
+	for _, tst := range tests {
+		if path := tst.p.PlanAvoiding(corridor); (path != nil) != tst.ok {
+			t.Errorf("%s: PlanAvoiding returned %v, want a path: %v", tst.name, path, tst.ok)
+		}
+	}
+}
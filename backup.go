@@ -0,0 +1,64 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// penaltyData wraps a Data interface such that entering any of the penalized
+// states costs an additional penalty.
+type penaltyData[S comparable] struct {
+	DataOf[S]
+	states  map[S]bool
+	penalty float64
+}
+
+func (p penaltyData[S]) Cost(u, v S) float64 {
+	if p.states[v] {
+		return p.DataOf.Cost(u, v) + p.penalty
+	}
+	return p.DataOf.Cost(u, v)
+}
+
+// BackupPath returns a fallback path that avoids the states of the current
+// primary path (as returned by Plan), for use should the primary path become
+// blocked. If there is no primary path, or no path distinct from it, nil is
+// returned.
+//
+// The states of the primary path (other than the start and goal) are
+// soft-penalized rather than made impassable: entering one costs more than
+// the whole primary path, so they are only reused where the backup path can't
+// avoid them (e.g. a shared chokepoint). The backup is planned incrementally
+// on a copy of the planner, which is left untouched apart from the call to
+// Plan.
+func (s *PlannerOf[S]) BackupPath() []S {
+	primary := s.Plan()
+	if len(primary) < 3 {
+		// No primary path, or one without any states to avoid.
+		return nil
+	}
+
+	cost := sumCost(s.d, primary)
+
+	overlay := penaltyData[S]{
+		DataOf:  s.d,
+		states:  make(map[S]bool, len(primary)),
+		penalty: cost + 1,
+	}
+	for _, st := range primary[1 : len(primary)-1] {
+		overlay.states[st] = true
+	}
+
+	c := s.clone(overlay)
+	for st := range overlay.states {
+		for _, u := range c.pred(st) {
+			cOld := s.d.Cost(u, st)
+			c.FlagChanged(u, st, cOld, cOld+overlay.penalty)
+		}
+	}
+
+	backup := c.Plan()
+	if backup == nil || s.pathsEqual(backup, primary) {
+		return nil
+	}
+	return backup
+}
@@ -0,0 +1,66 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestBackupPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       string
+		primary int // Row in which the primary path passes column 2.
+		backup  int // Row in which the backup path does, -1 if there is none.
+	}{
+		{"two corridors", `
+S.....G
+.#####.
+.......
+`, 0, 2},
+		{"shared chokepoint", `
+S....#
+.###.G
+.....#
+`, 0, 2},
+		{"single corridor", `
+S.....G
+#######
+`, 0, -1},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		p := dstarlite.New(g, start, goal)
+		primary := p.Plan()
+		backup := p.BackupPath()
+		if !reflect.DeepEqual(p.Plan(), primary) {
+			t.Errorf("%s: BackupPath changed the primary path", tst.name)
+		}
+		passes := func(path []dstarlite.State, row int) bool {
+			for _, s := range path {
+				if s == (grid.Coord{X: 2, Y: row}) {
+					return true
+				}
+			}
+			return false
+		}
+		if !passes(primary, tst.primary) {
+			t.Errorf("%s: primary path %v, want it through row %d", tst.name, primary, tst.primary)
+		}
+		if tst.backup < 0 {
+			if backup != nil {
+				t.Errorf("%s: got backup path %v, want nil", tst.name, backup)
+			}
+			continue
+		}
+		if backup == nil || !passes(backup, tst.backup) {
+			t.Errorf("%s: backup path %v, want it through row %d", tst.name, backup, tst.backup)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// EdgeChangeOf describes a change of the cost of traversal from state U to
+// state V, from COld to CNew, see FlagChangedBatch.
+type EdgeChangeOf[S comparable] struct {
+	U, V       S
+	COld, CNew float64
+}
+
+// EdgeChange is the EdgeChangeOf for State interfaces, as used by Planner.
+type EdgeChange = EdgeChangeOf[State]
+
+// FlagChangedBatch is like calling FlagChanged for each of the changes, but
+// more efficient when many edges change at once (e.g. a large obstacle
+// appears): the queue entry of each state is updated only once, no matter how
+// many of the changes leave it. The result of the next call to Plan is
+// identical to that of applying the changes one by one.
+func (s *PlannerOf[S]) FlagChangedBatch(changes []EdgeChangeOf[S]) {
+	if len(changes) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	touched := make(map[S]bool, len(changes))
+	var order []S
+	for _, c := range changes {
+		s.flagRHS(c.U, c.V, c.COld, c.CNew)
+		if !touched[c.U] {
+			touched[c.U] = true
+			order = append(order, c.U)
+		}
+	}
+	for _, u := range order {
+		s.updateVertex(u)
+	}
+	s.dirty = true
+	if s.propLimit > 0 && !s.fromScratch {
+		s.changed = append(s.changed, order...)
+	}
+}
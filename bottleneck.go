@@ -0,0 +1,78 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// NewBottleneck returns a new D* Lite Planner that plans bottleneck (minimax)
+// paths instead of shortest ones.
+//
+// The value of a path is the cost of its single most expensive edge, rather
+// than the sum of all of its edge costs, and Plan returns a path whose most
+// expensive edge is as cheap as possible (the widest or safest path). Such a
+// path may be much longer in total cost than the shortest path. Among several
+// paths with an equal bottleneck, the one with the fewest states is returned.
+//
+// Since Data.Dist bounds the sum of edge costs and not their maximum, it is
+// not used as a heuristic; bottleneck planners expand states in the order of
+// Dijkstra's algorithm.
+func NewBottleneck(data Data, start, goal State) *Planner {
+	dsl := New(data, start, goal)
+	dsl.bottleneck = true
+	dsl.u.Update(goal, dsl.calcKey(goal))
+	return dsl
+}
+
+// bottleneckWalk returns a path from the start to the goal over which no edge
+// costs more than the bottleneck value of the start state.
+//
+// Many states may share an equal bottleneck value, so following the gradient
+// like walk does could cycle. Instead a breadth-first search is performed
+// over the states whose value does not exceed that of the start, which yields
+// the optimal path with the fewest states.
+func (s *PlannerOf[S]) bottleneckWalk() []S {
+	limit := s.rhs.get(s.start)
+	if math.IsInf(limit, 1) {
+		return nil
+	}
+
+	within := func(v float64) bool {
+		return v <= limit || float64Equals(v, limit)
+	}
+
+	// The start is its own parent, which marks the root of the search.
+	parent := map[S]S{s.start: s.start}
+	queue := []S{s.start}
+	for len(queue) > 0 {
+		st := queue[0]
+		queue = queue[1:]
+
+		if s.isGoal(st) {
+			var path []S
+			for ; st != s.start; st = parent[st] {
+				path = append(path, st)
+			}
+			path = append(path, s.start)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+
+		for _, sPrime := range s.succ(st) {
+			if _, seen := parent[sPrime]; seen {
+				continue
+			}
+			if !within(s.combine(s.d.Cost(st, sPrime), s.g.get(sPrime))) {
+				continue
+			}
+			parent[sPrime] = st
+			queue = append(queue, sPrime)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestBottleneck(t *testing.T) {
+	tests := []struct {
+		name             string
+		g                graph
+		shortest, widest []dstarlite.State
+	}{
+		{
+			name: "expensive shortcut",
+			g: graph{
+				{"a", "b"}: 10, {"b", "d"}: 1,
+				{"a", "c"}: 4, {"c", "e"}: 4, {"e", "f"}: 4, {"f", "d"}: 4,
+			},
+			shortest: nodes("a", "b", "d"),
+			widest:   nodes("a", "c", "e", "f", "d"),
+		},
+		{
+			name: "equal bottlenecks",
+			g: graph{
+				{"a", "b"}: 5, {"b", "d"}: 5,
+				{"a", "c"}: 1, {"c", "e"}: 5, {"e", "f"}: 1, {"f", "d"}: 1,
+			},
+			shortest: nodes("a", "c", "e", "f", "d"),
+			widest:   nodes("a", "b", "d"),
+		},
+		{
+			name: "unreachable",
+			g:    graph{{"a", "b"}: 1, {"d", "b"}: 1},
+		},
+	}
+	for _, tst := range tests {
+		if path := dstarlite.New(tst.g, node("a"), node("d")).Plan(); !reflect.DeepEqual(path, tst.shortest) {
+			t.Errorf("%s: got shortest path %v, want %v", tst.name, path, tst.shortest)
+		}
+		if path := dstarlite.NewBottleneck(tst.g, node("a"), node("d")).Plan(); !reflect.DeepEqual(path, tst.widest) {
+			t.Errorf("%s: got bottleneck path %v, want %v", tst.name, path, tst.widest)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"sync"
+)
+
+// CachingData wraps a Data interface whose Cost and Dist methods are
+// expensive, memoizing their results per (ordered) pair of states; the
+// planner calls them repeatedly for the same pairs. Succ and Pred are passed
+// through.
+//
+// Cached costs must be dropped (see Invalidate) whenever the cost of an edge
+// changes, before the change is passed to FlagChanged. Dist results are cached
+// indefinitely. CachingData is safe for concurrent use.
+type CachingData struct {
+	Data
+
+	mu   sync.Mutex
+	cost map[[2]State]float64
+	dist map[[2]State]float64
+}
+
+// NewCachingData returns a new caching wrapper around the given data.
+func NewCachingData(d Data) *CachingData {
+	return &CachingData{
+		Data: d,
+		cost: make(map[[2]State]float64),
+		dist: make(map[[2]State]float64),
+	}
+}
+
+// Cost implements the Data interface.
+func (c *CachingData) Cost(a, b State) float64 {
+	return c.cached(c.cost, a, b, c.Data.Cost)
+}
+
+// Dist implements the Data interface.
+func (c *CachingData) Dist(a, b State) float64 {
+	return c.cached(c.dist, a, b, c.Data.Dist)
+}
+
+func (c *CachingData) cached(m map[[2]State]float64, a, b State, fn func(a, b State) float64) float64 {
+	key := [2]State{a, b}
+	c.mu.Lock()
+	v, ok := m[key]
+	c.mu.Unlock()
+	if ok {
+		return v
+	}
+	v = fn(a, b)
+	c.mu.Lock()
+	m[key] = v
+	c.mu.Unlock()
+	return v
+}
+
+// Invalidate drops the cached cost of the edge from u to v (but not that of
+// the edge from v to u).
+func (c *CachingData) Invalidate(u, v State) {
+	c.mu.Lock()
+	delete(c.cost, [2]State{u, v})
+	c.mu.Unlock()
+}
+
+// InvalidateAll drops all cached costs and distances.
+func (c *CachingData) InvalidateAll() {
+	c.mu.Lock()
+	c.cost = make(map[[2]State]float64)
+	c.dist = make(map[[2]State]float64)
+	c.mu.Unlock()
+}
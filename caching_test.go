@@ -0,0 +1,126 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// countingData counts the calls to the Cost and Dist methods of a Data
+// interface.
+type countingData struct {
+	dstarlite.Data
+	costs, dists int
+}
+
+func (c *countingData) Cost(a, b dstarlite.State) float64 {
+	c.costs++
+	return c.Data.Cost(a, b)
+}
+
+func (c *countingData) Dist(a, b dstarlite.State) float64 {
+	c.dists++
+	return c.Data.Dist(a, b)
+}
+
+func TestCachingDataAsymmetric(t *testing.T) {
+	a, b := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 1, Y: 0}
+	tests := []struct {
+		name           string
+		ab, ba         float64 // Door costs when they are cached.
+		newAB, newBA   float64 // Door costs when they are changed.
+		invalidate     [][2]dstarlite.State
+		wantAB, wantBA float64
+	}{
+		{"no invalidation", 2, 5, 3, 7, nil, 2, 5},
+		{"forward", 2, 5, 3, 7, [][2]dstarlite.State{{a, b}}, 3, 5},
+		{"backward", 2, 5, 3, 7, [][2]dstarlite.State{{b, a}}, 2, 7},
+		{"both", 2, 5, 3, 7, [][2]dstarlite.State{{a, b}, {b, a}}, 3, 7},
+	}
+	for _, tst := range tests {
+		g := grid.New(2, 1)
+		g.AddDoor(a, b, tst.ab, tst.ba)
+		c := dstarlite.NewCachingData(g)
+		if ab, ba := c.Cost(a, b), c.Cost(b, a); ab != tst.ab || ba != tst.ba {
+			t.Fatalf("%s: got costs %v and %v, want %v and %v", tst.name, ab, ba, tst.ab, tst.ba)
+		}
+		g.AddDoor(a, b, tst.newAB, tst.newBA)
+		for _, e := range tst.invalidate {
+			c.Invalidate(e[0], e[1])
+		}
+		if ab, ba := c.Cost(a, b), c.Cost(b, a); ab != tst.wantAB || ba != tst.wantBA {
+			t.Errorf("%s: got costs %v and %v, want %v and %v", tst.name, ab, ba, tst.wantAB, tst.wantBA)
+		}
+	}
+}
+
+func TestCachingDataPaths(t *testing.T) {
+	// A 4-connected maze, in which only the edges into and out of a cell
+	// depend on whether it is blocked.
+	g := maze(21, 5)
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	live := dstarlite.New(g, start, goal)
+	c := dstarlite.NewCachingData(g)
+	cached := dstarlite.New(c, start, goal)
+	for i := 0; i < 3; i++ {
+		want, path := live.Plan(), cached.Plan()
+		if !reflect.DeepEqual(path, want) {
+			t.Fatalf("plan %d: cached path %v, want %v", i, path, want)
+		}
+
+		// Open a cell, invalidating the costs of its edges before flagging
+		// them.
+		cell := grid.Coord{X: 3 + 4*i, Y: 1}
+		var old []float64
+		succ := g.Succ(cell)
+		for _, n := range succ {
+			old = append(old, g.Cost(cell, n), g.Cost(n, cell))
+		}
+		g.SetBlocked(cell, false)
+		for j, n := range succ {
+			c.Invalidate(cell, n)
+			c.Invalidate(n, cell)
+			for _, p := range []*dstarlite.Planner{live, cached} {
+				p.FlagChanged(cell, n, old[2*j], g.Cost(cell, n))
+				p.FlagChanged(n, cell, old[2*j+1], g.Cost(n, cell))
+			}
+		}
+	}
+}
+
+// The benchmarks below replan after moving along the path of a maze, with
+// and without caching, and report the calls made to Cost and Dist of the
+// underlying data per operation.
+
+func benchmarkCaching(b *testing.B, cache bool) {
+	g := maze(41, 5)
+	counter := &countingData{Data: g}
+	for i := 0; i < b.N; i++ {
+		var d dstarlite.Data = counter
+		if cache {
+			d = dstarlite.NewCachingData(counter)
+		}
+		p := dstarlite.New(d, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 40, Y: 40})
+		path := p.Plan()
+		for _, st := range path[1:] {
+			p.UpdateStart(st)
+			p.Plan()
+		}
+	}
+	b.ReportMetric(float64(counter.costs)/float64(b.N), "costs/op")
+	b.ReportMetric(float64(counter.dists)/float64(b.N), "dists/op")
+}
+
+func BenchmarkUncachedData(b *testing.B) {
+	benchmarkCaching(b, false)
+}
+
+func BenchmarkCachingData(b *testing.B) {
+	benchmarkCaching(b, true)
+}
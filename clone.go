@@ -0,0 +1,78 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"sync"
+)
+
+func (v valueMap[S]) clone() valueMap[S] {
+	c := make(valueMap[S], len(v))
+	for s, val := range v {
+		c[s] = val
+	}
+	return c
+}
+
+func (q *priorityQueue[S]) clone() *priorityQueue[S] {
+	c := new(priorityQueue[S])
+	c.lookups = make(map[S]int, len(q.lookups))
+	for s, i := range q.lookups {
+		c.lookups[s] = i
+	}
+	c.items = append(make([]pqItem[S], 0, len(q.items)), q.items...)
+	c.seq = q.seq
+	return c
+}
+
+// cloneQueue returns a copy of the priority queue of the planner. Queues other
+// than the default one are copied by inserting the items in pop order (see
+// QueueSnapshot).
+func (s *PlannerOf[S]) cloneQueue() PriorityQueueOf[S] {
+	if q, ok := s.u.(*priorityQueue[S]); ok {
+		return q.clone()
+	}
+	c := s.newQueue()
+	for _, item := range s.QueueSnapshot() {
+		c.Insert(item.State, item.Key)
+	}
+	return c
+}
+
+// clone returns a deep copy of the planner that plans through the given data
+// instead. The copy does not share any mutable state with the original, nor
+// its history, trace writer, hooks or watched conditions.
+func (s *PlannerOf[S]) clone(data DataOf[S]) *PlannerOf[S] {
+	c := *s
+	c.mu = new(sync.RWMutex)
+	c.d = data
+	c.goals = s.goals.clone()
+	if s.blend != nil {
+		c.blend = s.blend.clone()
+	}
+	c.rhs = s.rhs.clone()
+	c.g = s.g.clone()
+	c.u = s.cloneQueue()
+	c.changed = append([]S(nil), s.changed...)
+	c.preds = nil
+	c.history = nil
+	c.traceW = nil
+	c.onIteration = nil
+	c.onExpand = nil
+	c.onUpdateVertex = nil
+	c.watches = nil
+	return &c
+}
+
+// Clone returns a deep copy of the planner, planning through the same Data
+// interface, which can be used for what-if simulations: changes made to the
+// copy (through FlagChanged, UpdateStart, etc.) do not affect the original,
+// and vice versa. The copy does not inherit the history, trace writer, hooks
+// or watched conditions of the original.
+func (s *PlannerOf[S]) Clone() *PlannerOf[S] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clone(s.d)
+}
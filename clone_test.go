@@ -0,0 +1,65 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestCloneIsIndependent(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *dstarlite.Planner, path []dstarlite.State)
+	}{
+		{"edge on path blocked", func(c *dstarlite.Planner, path []dstarlite.State) {
+			c.FlagChanged(path[1], path[2], 1, math.Inf(1))
+			c.Plan()
+		}},
+		{"start moved", func(c *dstarlite.Planner, path []dstarlite.State) {
+			c.UpdateStart(path[3])
+			c.Plan()
+		}},
+		{"precomputed", func(c *dstarlite.Planner, path []dstarlite.State) {
+			c.Precompute()
+		}},
+	}
+	for _, tst := range tests {
+		g := maze(21, 4)
+		p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20})
+		want := p.Plan()
+		queue := p.QueueSnapshot()
+
+		tst.mutate(p.Clone(), want)
+		if got := p.QueueSnapshot(); !reflect.DeepEqual(got, queue) {
+			t.Errorf("%s: original queue changed", tst.name)
+		}
+		if got := p.Plan(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: original planned %v, want %v", tst.name, got, want)
+		}
+		if n := p.LastExpansions(); n != 0 {
+			t.Errorf("%s: original expanded %d vertices after the clone changed", tst.name, n)
+		}
+	}
+}
+
+func TestCloneBlended(t *testing.T) {
+	left, right := grid.Coord{X: 0, Y: 10}, grid.Coord{X: 10, Y: 10}
+	p := dstarlite.NewBlended(grid.New(11, 11), grid.Coord{X: 5, Y: 0}, []dstarlite.State{left, right}, []float64{1, 1})
+	want := p.Plan()
+
+	// Removing an attractor from the copy keeps the attractor weights of the
+	// original, which break the ties of its path.
+	c := p.Clone()
+	c.RemoveGoal(right)
+	c.Plan()
+	if got := p.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("original planned %v, want %v", got, want)
+	}
+}
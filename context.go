@@ -0,0 +1,73 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultCancelInterval is the default number of expansions between checks of
+// the context passed to PlanContext.
+const DefaultCancelInterval = 1024
+
+// PlanContext is like PlanE, except that computing the shortest path is
+// aborted once ctx is done, in which case the error of the context is
+// returned.
+//
+// The context is checked every few expansions (see SetCancelInterval) rather
+// than on every one, to keep the overhead low. An aborted plan leaves the
+// planner in a consistent state: a subsequent call to Plan (or PlanContext)
+// resumes from where it left off instead of starting over.
+func (s *PlannerOf[S]) PlanContext(ctx context.Context) ([]S, error) {
+	return s.plan(ctx, nil)
+}
+
+// SetCancelInterval sets the number of expansions between checks of the
+// context passed to PlanContext. Lower values make cancellation more timely
+// at a higher cost. Values less than one restore DefaultCancelInterval.
+func (s *PlannerOf[S]) SetCancelInterval(n int) {
+	s.checkEvery = n
+}
+
+func (s *PlannerOf[S]) cancelInterval() int {
+	if s.checkEvery < 1 {
+		return DefaultCancelInterval
+	}
+	return s.checkEvery
+}
+
+// PlanWithin is like Plan, except that computing the shortest path stops once
+// the given time budget elapses, e.g. to bound the time spent pathfinding per
+// frame. The context mechanism of PlanContext is used, so the clock is only
+// checked every few expansions (see SetCancelInterval).
+//
+// It returns the path and true if planning finished within the budget, also
+// when it found that there is no path (in which case the path is nil).
+// Otherwise, when the budget or a limit on the work (see SetMaxExpansions and
+// SetMaxNodes) stopped it, it returns the best path that can be extracted from
+// the partial work (which may be suboptimal, or nil) and false, and the next
+// call to Plan or PlanWithin continues where this one stopped.
+func (s *PlannerOf[S]) PlanWithin(budget time.Duration) ([]S, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+	path, err := s.PlanContext(ctx)
+	switch err {
+	case context.DeadlineExceeded, ErrExpansionLimit, ErrNodeLimit:
+	default:
+		return path, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bottleneck {
+		return s.bottleneckWalk(), false
+	}
+	path, err = s.walk(nil)
+	if err != nil {
+		return nil, false
+	}
+	return path, false
+}
@@ -0,0 +1,115 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// lattice returns a graph of size by size nodes named "x,y", with edges in
+// both directions between horizontal and vertical neighbors, whose costs are
+// drawn from 1 to 9 by a random source seeded by seed.
+func lattice(size int, seed int64) graph {
+	r := rand.New(rand.NewSource(seed))
+	g := make(graph)
+	name := func(x, y int) node { return node(fmt.Sprintf("%d,%d", x, y)) }
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if x+1 < size {
+				g[[2]node{name(x, y), name(x+1, y)}] = float64(1 + r.Intn(9))
+				g[[2]node{name(x+1, y), name(x, y)}] = float64(1 + r.Intn(9))
+			}
+			if y+1 < size {
+				g[[2]node{name(x, y), name(x, y+1)}] = float64(1 + r.Intn(9))
+				g[[2]node{name(x, y+1), name(x, y)}] = float64(1 + r.Intn(9))
+			}
+		}
+	}
+	return g
+}
+
+// maxCost returns the cost of the most expensive edge of the path, or +Inf
+// for a nil path.
+func maxCost(d dstarlite.Data, path []dstarlite.State) float64 {
+	if path == nil {
+		return math.Inf(1)
+	}
+	var cost float64
+	for i := 1; i < len(path); i++ {
+		cost = math.Max(cost, d.Cost(path[i-1], path[i]))
+	}
+	return cost
+}
+
+func TestPlanWithinSplitsPlan(t *testing.T) {
+	start, goal := node("0,0"), node("7,7")
+	tests := []struct {
+		name string
+		new  func(d dstarlite.Data, start, goal dstarlite.State) *dstarlite.Planner
+		cost func(d dstarlite.Data, path []dstarlite.State) float64
+	}{
+		{"shortest", func(d dstarlite.Data, start, goal dstarlite.State) *dstarlite.Planner {
+			return dstarlite.New(d, start, goal)
+		}, pathCost},
+		{"bottleneck", dstarlite.NewBottleneck, maxCost},
+	}
+	for _, tst := range tests {
+		for _, limit := range []int{1, 5, 20} {
+			g := lattice(8, 1)
+			p := tst.new(g, start, goal)
+			path := p.Plan()
+			before := tst.cost(g, path)
+
+			// Making the edges along the top and right borders cheap makes the
+			// planner replan, which the expansion limit splits over several
+			// calls (the budget is ample). Until it is done, the paths come
+			// from the partial work.
+			for i := 0; i < 7; i++ {
+				for _, e := range [][2]node{
+					{node(fmt.Sprintf("%d,0", i)), node(fmt.Sprintf("%d,0", i+1))},
+					{node(fmt.Sprintf("7,%d", i)), node(fmt.Sprintf("7,%d", i+1))},
+				} {
+					old := g[e]
+					g[e] = 1
+					p.FlagChanged(e[0], e[1], old, 1)
+				}
+			}
+			p.SetMaxExpansions(limit)
+			var (
+				ok    bool
+				calls int
+			)
+			for !ok {
+				path, ok = p.PlanWithin(time.Hour)
+				if ok {
+					break
+				}
+				// Costs only decreased, so the path planned before the change
+				// stays valid and the partial work yields one at least as good.
+				if path == nil || path[0] != start || path[len(path)-1] != goal {
+					t.Errorf("%s, limit %d: partial path %v does not lead from start to goal", tst.name, limit, path)
+				} else if c := tst.cost(g, path); c > before {
+					t.Errorf("%s, limit %d: partial path %v costs %v, more than %v before the change", tst.name, limit, path, c, before)
+				}
+				if calls++; calls > 1000 {
+					t.Fatalf("%s, limit %d: not done after %d calls", tst.name, limit, calls)
+				}
+			}
+			if limit == 1 && calls < 2 {
+				t.Errorf("%s, limit %d: planning was not split", tst.name, limit)
+			}
+			want := tst.new(g, start, goal).Plan()
+			if got, want := tst.cost(g, path), tst.cost(g, want); got != want {
+				t.Errorf("%s, limit %d: got path %v of cost %v, want cost %v as by Plan", tst.name, limit, path, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"errors"
+)
+
+// ErrGradientCycle is returned by PlanE when following the gradient of the
+// computed costs from the start would revisit a state, instead of looping
+// forever. The path up to (but excluding) the revisited state is returned
+// alongside it.
+var ErrGradientCycle = errors.New("dstarlite: cycle in gradient walk")
+
+// CyclePolicy decides how a planner handles cycles in the gradient walk.
+//
+// The gradient walk from the start to the goal assumes that the cost-to-goal
+// strictly decreases along the best successor of each state, but zero cost
+// edges or floating point error can make it revisit a state.
+type CyclePolicy int
+
+const (
+	// CycleError stops the walk at the first revisited state, and returns
+	// the path so far with ErrGradientCycle. This is the default.
+	CycleError CyclePolicy = iota
+
+	// CycleBestEffort continues the walk from the cycle with the best
+	// successor that was not yet visited, only failing (with
+	// ErrGradientCycle) if every successor was visited.
+	CycleBestEffort
+)
+
+// SetCyclePolicy sets how the planner handles cycles in the gradient walk.
+func (s *PlannerOf[S]) SetCyclePolicy(p CyclePolicy) {
+	s.cyclePolicy = p
+	s.walked = false
+}
@@ -0,0 +1,45 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestGradientCycle(t *testing.T) {
+	// a and b are joined by zero cost edges, so both have a cost-to-goal of
+	// one; with the whole field computed and successors visited in name
+	// order, the walk prefers moving between them over moving to the goal z.
+	g := graph{{"a", "b"}: 0, {"b", "a"}: 0, {"a", "z"}: 1, {"b", "z"}: 1}
+	byName := func(s dstarlite.State, succ []dstarlite.State) {
+		sort.Slice(succ, func(i, j int) bool { return succ[i].(node) < succ[j].(node) })
+	}
+	tests := []struct {
+		name   string
+		policy dstarlite.CyclePolicy
+		path   []dstarlite.State
+		err    error
+	}{
+		{"error", dstarlite.CycleError, nodes("a", "b"), dstarlite.ErrGradientCycle},
+		{"best effort", dstarlite.CycleBestEffort, nodes("a", "b", "z"), nil},
+	}
+	for _, tst := range tests {
+		p := dstarlite.New(g, node("a"), node("z"))
+		p.SetSuccessorOrder(byName)
+		p.SetCyclePolicy(tst.policy)
+		p.Precompute()
+		path, err := p.PlanE()
+		if err != tst.err || !reflect.DeepEqual(path, tst.path) {
+			t.Errorf("%s: got %v, %v; want %v, %v", tst.name, path, err, tst.path, tst.err)
+		}
+		if got := p.Plan(); (got == nil) != (tst.err != nil) {
+			t.Errorf("%s: Plan returned %v", tst.name, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// Diagnosis describes why the goal is (or is not) reachable from the start.
+type Diagnosis int
+
+const (
+	// Reachable means a path from the start to the goal exists.
+	Reachable Diagnosis = iota
+
+	// StartSealed means the start is walled into a region that is smaller
+	// than the region around the goal.
+	StartSealed
+
+	// GoalSealed means the goal is walled into a region that is smaller than
+	// the region around the start.
+	GoalSealed
+
+	// Disconnected means the start and goal lie in separate regions of equal
+	// extent, such that neither can be said to be the sealed one.
+	Disconnected
+)
+
+func (d Diagnosis) String() string {
+	switch d {
+	case Reachable:
+		return "reachable"
+	case StartSealed:
+		return "start sealed"
+	case GoalSealed:
+		return "goal sealed"
+	case Disconnected:
+		return "disconnected"
+	}
+	return "Diagnosis(invalid)"
+}
+
+// flood is a single breadth-first flood fill over the states connected by
+// finite cost edges.
+type flood[S comparable] struct {
+	seen     map[S]bool
+	frontier []S
+}
+
+func newFlood[S comparable](states ...S) *flood[S] {
+	f := &flood[S]{seen: make(map[S]bool, len(states))}
+	for _, s := range states {
+		f.seen[s] = true
+		f.frontier = append(f.frontier, s)
+	}
+	return f
+}
+
+// reached tells if the flood reached any of the states in the set.
+func (f *flood[S]) reached(set valueMap[S]) bool {
+	for s := range set {
+		if f.seen[s] {
+			return true
+		}
+	}
+	return false
+}
+
+// step expands the next state of the flood, next returns the states adjacent
+// to it. It returns false once the flood is exhausted.
+func (f *flood[S]) step(next func(S) []S, cost func(a, b S) float64) bool {
+	if len(f.frontier) == 0 {
+		return false
+	}
+	st := f.frontier[0]
+	f.frontier = f.frontier[1:]
+	for _, n := range next(st) {
+		if f.seen[n] || math.IsInf(cost(st, n), 1) {
+			continue
+		}
+		f.seen[n] = true
+		f.frontier = append(f.frontier, n)
+	}
+	return true
+}
+
+// Diagnose tells why Plan cannot find a path, if it cannot.
+//
+// It floods outwards from the start (over successors) and from the goals (over
+// predecessors) in lockstep, following only edges of finite cost. If the flood
+// from the start reaches the goal there is a path. Otherwise whichever flood
+// runs out of states first is the side that is walled in. Since the floods
+// advance together, only about twice the size of the smaller region is
+// visited, even when the other region is very large.
+func (s *PlannerOf[S]) Diagnose() Diagnosis {
+	fwd := newFlood(s.start)
+	var goals []S
+	for g := range s.goals {
+		goals = append(goals, g)
+	}
+	bwd := newFlood(goals...)
+	cost := s.d.Cost
+	revCost := func(a, b S) float64 { return s.d.Cost(b, a) }
+	for {
+		if fwd.reached(s.goals) {
+			return Reachable
+		}
+		fwdMore := fwd.step(s.succ, cost)
+		bwdMore := bwd.step(s.pred, revCost)
+		switch {
+		case fwd.reached(s.goals):
+			return Reachable
+		case !fwdMore && !bwdMore:
+			return Disconnected
+		case !fwdMore:
+			return StartSealed
+		case !bwdMore:
+			return GoalSealed
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name string
+		m    string
+		want dstarlite.Diagnosis
+	}{
+		{"reachable", `
+S.#...
+..#.#.
+....#G
+`, dstarlite.Reachable},
+		{"start sealed", `
+S#....
+##....
+.....G
+`, dstarlite.StartSealed},
+		{"goal sealed", `
+S.....
+....##
+....#G
+`, dstarlite.GoalSealed},
+		{"disconnected", `
+S..#...
+...#...
+...#..G
+`, dstarlite.Disconnected},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		p := dstarlite.New(g, start, goal)
+		if got := p.Diagnose(); got != tst.want {
+			t.Errorf("%s: Diagnose() = %v, want %v", tst.name, got, tst.want)
+		}
+		if path := p.Plan(); (path != nil) != (tst.want == dstarlite.Reachable) {
+			t.Errorf("%s: got path %v with diagnosis %v", tst.name, path, tst.want)
+		}
+	}
+}
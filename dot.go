@@ -0,0 +1,77 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// WriteDOT writes the search state of the planner, restricted to the given
+// states, to w as a GraphViz DOT digraph, e.g. to spot inconsistent vertices
+// with:
+//
+//  dot -Tsvg search.dot > search.svg
+//
+// Each state is a node labeled with label(st) followed by its g and rhs values
+// and, if it is in the priority queue, its key. Locally inconsistent states
+// (whose g and rhs values differ) are drawn in red and queued states are
+// filled. Each edge from a state to one of its successors (as returned by
+// Succ) that is also in the given states is labeled with its cost, and dashed
+// if the cost is +Inf.
+//
+// The first write error, if any, is returned.
+func (s *PlannerOf[S]) WriteDOT(w io.Writer, states []S, label func(S) string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	ids := make(map[S]int, len(states))
+	for i, st := range states {
+		ids[st] = i
+	}
+
+	printf("digraph dstarlite {\n")
+	queued := make(map[S]Key)
+	s.u.Each(func(st S, k Key) {
+		queued[st] = k
+	})
+	for i, st := range states {
+		g, rhs := s.g.get(st), s.rhs.get(st)
+		text := fmt.Sprintf("%s\ng=%v rhs=%v", label(st), g, rhs)
+		attrs := ""
+		if k, ok := queued[st]; ok {
+			text += fmt.Sprintf("\nkey=%v", k)
+			attrs += " style=filled"
+		}
+		if !float64Equals(g, rhs) {
+			attrs += " color=red"
+		}
+		printf("\tn%d [label=%q%s];\n", i, text, attrs)
+	}
+	for i, st := range states {
+		for _, sPrime := range s.d.Succ(st) {
+			j, ok := ids[sPrime]
+			if !ok {
+				continue
+			}
+			c := s.d.Cost(st, sPrime)
+			attrs := ""
+			if math.IsInf(c, 1) {
+				attrs = " style=dashed"
+			}
+			printf("\tn%d -> n%d [label=\"%v\"%s];\n", i, j, c, attrs)
+		}
+	}
+	printf("}\n")
+	return err
+}
@@ -0,0 +1,50 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestWriteDOT(t *testing.T) {
+	g := graph{{"a", "b"}: 1, {"b", "c"}: 1, {"d", "c"}: 2}
+	p := dstarlite.New(g, node("a"), node("c"))
+	p.Plan()
+
+	// Blocking the edge out of b leaves b inconsistent and queued, as the
+	// start already is once the path was found.
+	g[[2]node{"b", "c"}] = math.Inf(1)
+	p.FlagChanged(node("b"), node("c"), 1, math.Inf(1))
+
+	var buf bytes.Buffer
+	label := func(s dstarlite.State) string { return string(s.(node)) }
+	if err := p.WriteDOT(&buf, nodes("a", "b", "c"), label); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph dstarlite {
+	n0 [label="a\ng=+Inf rhs=2\nkey=Key(2, 2)" style=filled color=red];
+	n1 [label="b\ng=1 rhs=+Inf\nkey=Key(1, 1)" style=filled color=red];
+	n2 [label="c\ng=0 rhs=0"];
+	n0 -> n1 [label="1"];
+	n1 -> n2 [label="+Inf" style=dashed];
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Edges to states not given are left out, and write errors are returned.
+	buf.Reset()
+	if err := p.WriteDOT(&buf, nodes("a", "d"), label); err != nil || bytes.Contains(buf.Bytes(), []byte("->")) {
+		t.Errorf("got %v writing\n%s\nwant no edges", err, buf.String())
+	}
+	if err := p.WriteDOT(&failingWriter{}, nodes("a"), label); err != errWrite {
+		t.Errorf("got error %v from a failing writer, want %v", err, errWrite)
+	}
+}
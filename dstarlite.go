@@ -16,7 +16,12 @@
 package dstarlite
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"math"
+	"sync"
 )
 
 // State represents an single DSL state.
@@ -25,27 +30,44 @@ type State interface {
 	Equals(other State) bool
 }
 
+// Tolerance is the relative tolerance within which the planner considers two
+// g or rhs values equal. For values of magnitude below one it is used as an
+// absolute tolerance instead.
+//
+// Costs accumulate floating point error, and values that should be equal may
+// differ slightly; comparing them exactly makes the planner needlessly move
+// states in and out of the priority queue. It should be far smaller than the
+// smallest edge cost relative to the largest path cost, and must not be
+// modified while planning.
+var Tolerance = 1e-9
+
 // Simple float64 epsilon comparence
 func float64Equals(a, b float64) bool {
 	if a == b {
 		return true
 	}
 
-	if math.Abs(a-b) < math.SmallestNonzeroFloat64 {
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+	scale := math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+	if math.Abs(a-b) <= Tolerance*scale {
 		return true
 	}
 	return false
 }
 
-// Data is the data that the DSL Planner struct will plan through.
+// DataOf is the data that a PlannerOf plans through, for states of the
+// comparable type S (e.g. a struct{X, Y int}), which are used directly as map
+// keys.
 //
 // See dstarlite/grid for example usage.
-type Data interface {
+type DataOf[S comparable] interface {
 	// Succ should return an slice of successors to the specified state.
-	Succ(s State) []State
+	Succ(s S) []S
 
 	// Pred should return an slice of predecessors to the specified state.
-	Pred(s State) []State
+	Pred(s S) []S
 
 	// Dist should return the distance between the two states. In actual use
 	// the second vertex will always be the goal state.
@@ -55,7 +77,7 @@ type Data interface {
 	//  Dist(a, a) == 0
 	//  Dist(a, b) <= Cost(a, c) + Dist(c, b) (where a and c are neighbors)
 	//
-	Dist(a, b State) float64
+	Dist(a, b S) float64
 
 	// Cost should return the exact cost for the distance between two
 	// neighboring states.
@@ -63,176 +85,884 @@ type Data interface {
 	// The result for non-neighboring states is undefined.
 	//
 	// Note: Neighbors can be determined by the Pred() and Succ() functions.
-	Cost(a, b State) float64
+	Cost(a, b S) float64
 }
 
-// Planner plans an path through DSL Data.
-type Planner struct {
-	d           Data
-	start, goal State
-	rhs, g      valueMap
-	u           *priorityQueue
+// Data is the data that the DSL Planner struct will plan through, i.e. DataOf
+// for State interfaces.
+type Data = DataOf[State]
+
+// PlannerOf plans an path through DataOf, for states of the comparable type
+// S. Keying its maps on the states directly avoids boxing every state in an
+// interface, and hence many allocations; Planner is the instantiation for
+// State interfaces, which most of this package is documented in terms of.
+//
+// Plan (and its variants PlanE, PlanWrite and PlanContext), UpdateStart,
+// FlagChanged, FlagChangedBatch, AddGoal, RemoveGoal, UpdateGoal, Clone,
+// Start, Goal, G and RHS are safe to call from multiple goroutines: they are
+// serialized by a lock, such that e.g. a FlagChanged call blocks until a
+// concurrent Plan call has finished, while the read-only Clone, Start, Goal, G
+// and RHS may run concurrently with each other. Other methods must not be
+// called concurrently with any method, and hooks (like those set by
+// OnIteration) must not call methods of the planner. The Data interface must
+// itself be safe to read while another goroutine calls these methods.
+type PlannerOf[S comparable] struct {
+	// Guards the planner state, see the concurrency notes above.
+	mu *sync.RWMutex
+
+	options[S]
+
+	// State.Equals for State states, or nil to compare states with ==, see
+	// eq.
+	equals func(a, b S) bool
+
+	d           DataOf[S]
+	start, goal S
+	goals       valueMap[S]
+	rhs, g      valueMap[S]
+	u           PriorityQueueOf[S]
 	km          float64
+
+	// Result of the last gradient walk, which Plan returns again while the
+	// planner is not dirty. Walked tells if it is valid.
+	walked   bool
+	walkPath []S
+	walkErr  error
+
+	// dirty is set whenever the planner must run computeShortestPath again
+	// before the gradient walk in Plan is valid.
+	dirty bool
+
+	// Number of vertices expanded by the last call to Plan.
+	expansions int
+
+	// Scratch buffer for the predecessors of the vertex being expanded.
+	preds []S
+
+	// Other work done by the last call to Plan, see Stats.
+	stats Stats
+
+	// Number of vertices expanded when planning from scratch, see ReuseRatio.
+	fullExpansions int
+	fromScratch    bool
+
+	// Optional per-iteration hook, see OnIteration.
+	onIteration func(startKey, topKey Key)
+
+	// Optional per-expansion hook, see OnExpand.
+	onExpand func(st S, k Key, overconsistent bool)
+
+	// Optional per-update hook, see OnUpdateVertex.
+	onUpdateVertex func(st S, action VertexAction, k Key)
+
+	// Optional writer for the expansion log, see SetTrace.
+	traceW io.Writer
+
+	// Cost of the last path returned by Plan, see PathCost.
+	pathCost float64
+
+	// The most recent paths returned by Plan, oldest first.
+	history [][]S
+
+	// Watched conditions, see WatchCondition.
+	watches []*watch[S]
+
+	// Number of times the km limit was hit, see SetMaxKm.
+	reinits int
+
+	// Attractor weights of a blended planner, see NewBlended.
+	blend valueMap[S]
+
+	// States changed since the last call to Plan, see SetPropagationLimit.
+	changed []S
+
+	// Start and km before the last call to UpdateStart, see UndoUpdateStart.
+	// They are only valid if canUndo is set.
+	undoStart S
+	undoKm    float64
+	canUndo   bool
+}
+
+// Planner plans an path through DSL Data, see PlannerOf.
+type Planner = PlannerOf[State]
+
+// options holds the configuration of a planner, as set by its constructor,
+// Option arguments and setters, such that it can be carried over to another
+// planner as a whole (see fresh). Hooks and writers, which observe a planner
+// rather than configure it, are not part of it.
+type options[S comparable] struct {
+	// Optional constructor of the priority queue, see the Queue option.
+	queue func() PriorityQueueOf[S]
+
+	// Optional heuristic used in place of Dist for keys, see
+	// NewWithHeuristic.
+	h func(a, b S) float64
+
+	// Optional successor ordering function, see SetSuccessorOrder.
+	order func(s S, succ []S)
+
+	// Whether or not this is a bottleneck planner, see NewBottleneck.
+	bottleneck bool
+
+	// Quantum that computed costs are rounded to, see SetCostQuantum.
+	quantum float64
+
+	// Heuristic inflation factor, see SetEpsilon.
+	epsilon float64
+
+	// Limit on km, see SetMaxKm.
+	maxKm float64
+
+	// How gradient walk cycles are handled, see SetCyclePolicy.
+	cyclePolicy CyclePolicy
+
+	// Limit on the expansions of a single call to Plan, see
+	// SetMaxExpansions.
+	maxExpansions int
+
+	// Limit on the number of states tracked, see SetMaxNodes.
+	maxNodes int
+
+	// Number of expansions between checks of the context passed to
+	// PlanContext, see SetCancelInterval.
+	checkEvery int
+
+	// Propagation radius, see SetPropagationLimit.
+	propLimit float64
 }
 
 // Start returns the start state, as it is currently.
-func (s *Planner) Start() State {
+func (s *PlannerOf[S]) Start() S {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.start
 }
 
 // Goal returns the goal state, as it is currently.
-func (s *Planner) Goal() State {
+func (s *PlannerOf[S]) Goal() S {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.goal
 }
 
-func (s *Planner) calcKey(st State) key {
-	a := math.Min(s.g.get(st), s.rhs.get(st)) + s.d.Dist(s.start, st) + s.km
+// G returns the current g value (the cost-to-goal estimate) of the state, or
+// +Inf if the planner has not computed one. It is meant for inspection, e.g.
+// to draw the cost field.
+func (s *PlannerOf[S]) G(st S) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.g.get(st)
+}
+
+// RHS returns the current rhs value (the one-step lookahead cost-to-goal) of
+// the state, or +Inf if the planner has not computed one. States whose g and
+// rhs values differ are inconsistent, and queued for expansion.
+func (s *PlannerOf[S]) RHS(st S) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rhs.get(st)
+}
+
+// Dirty tells if the planner has pending changes (from FlagChanged or
+// UpdateStart) that the next call to Plan must process before it can walk the
+// path. When it returns false, Plan skips computing the shortest path
+// entirely, and if it already walked the path since the last change, returns
+// (a copy of) that path again without walking it, which makes calling Plan
+// every frame nearly free while nothing changes. A new planner is dirty.
+func (s *PlannerOf[S]) Dirty() bool {
+	return s.dirty
+}
+
+// LastExpansions returns the number of vertices expanded by the last call to
+// Plan. It is zero if Plan had no pending changes to process.
+func (s *PlannerOf[S]) LastExpansions() int {
+	return s.expansions
+}
+
+// PathCost returns the total cost of the last path returned by Plan, i.e. the
+// sum of the edge costs along it. The cost is computed once by Plan, so
+// calling PathCost does not invoke the Data interface. It is +Inf if no path
+// was found (or Plan was not called yet), and zero if the start is the goal.
+func (s *PlannerOf[S]) PathCost() float64 {
+	return s.pathCost
+}
+
+// ReuseRatio returns the number of vertices expanded by the last call to Plan
+// divided by the number expanded when planning from scratch. A low ratio means
+// the last replan reused most of the previous work.
+//
+// The ratio is approximate: the from scratch count is recorded by the first
+// call to Plan, and only refreshed when a later replan expands even more
+// vertices (such that the ratio never exceeds one). It is zero before the
+// first call to Plan.
+func (s *PlannerOf[S]) ReuseRatio() float64 {
+	if s.fullExpansions == 0 {
+		return 0
+	}
+	return float64(s.expansions) / float64(s.fullExpansions)
+}
+
+// SetSuccessorOrder sets a function that the planner calls to reorder the
+// successors (or predecessors) of state s, as returned by the Data interface,
+// before iterating over them.
+//
+// Among states of equal priority the iteration order decides which of several
+// equal-cost paths is found, so the function can be used to impose a
+// deterministic or locality-friendly (e.g. memory order) ordering. It must
+// sort the slice in place, and must not add or remove elements. Passing nil
+// restores the order given by the Data interface.
+func (s *PlannerOf[S]) SetSuccessorOrder(order func(s S, succ []S)) {
+	s.order = order
+	s.walked = false
+}
+
+// succ returns the successors of u, as ordered by the successor order.
+func (s *PlannerOf[S]) succ(u S) []S {
+	succ := s.d.Succ(u)
+	if s.order != nil {
+		// Sort a copy, as the Data interface may share the slice.
+		succ = append([]S(nil), succ...)
+		s.order(u, succ)
+	}
+	return succ
+}
+
+// pred returns the predecessors of u, as ordered by the successor order.
+func (s *PlannerOf[S]) pred(u S) []S {
+	pred := s.d.Pred(u)
+	if s.order != nil {
+		pred = append([]S(nil), pred...)
+		s.order(u, pred)
+	}
+	return pred
+}
+
+// combine returns the value of reaching the goal through an edge of cost c
+// leading to a state whose value is g. Normally this is their sum, but
+// bottleneck planners (see NewBottleneck) take the maximum instead.
+func (s *PlannerOf[S]) combine(c, g float64) float64 {
+	if math.IsInf(c, 1) || math.IsInf(g, 1) {
+		// Unreachable, explicitly: no arithmetic is done on infinities.
+		return math.Inf(1)
+	}
+	var v float64
+	if s.bottleneck {
+		v = math.Max(c, g)
+	} else {
+		v = c + g
+	}
+	if s.quantum > 0 && !math.IsInf(v, 0) {
+		v = math.Ceil(v/s.quantum) * s.quantum
+	}
+	return v
+}
+
+// SetCostQuantum makes the planner round every g and rhs value it computes up
+// to the next multiple of q. A quantum of zero (the default) disables
+// rounding.
+//
+// Costs that are not exactly representable (like the sqrt(2) of diagonal grid
+// moves) accumulate floating point error, such that values which should be
+// equal differ slightly; the planner then considers states inconsistent and
+// needlessly moves them in and out of the priority queue. Rounding stabilizes
+// these comparisons at the cost of a precision loss of up to q per edge.
+// Values are rounded up, never down, such that the Dist heuristic still never
+// overestimates the rounded costs.
+//
+// The quantum should be a power of two (whose multiples are exactly
+// representable) far smaller than the smallest edge cost, e.g. 1.0/(1<<30),
+// and should be set before the first call to Plan.
+func (s *PlannerOf[S]) SetCostQuantum(q float64) {
+	s.quantum = q
+	s.walked = false
+}
+
+func (s *PlannerOf[S]) calcKey(st S) Key {
+	if math.IsInf(s.g.get(st), 1) && math.IsInf(s.rhs.get(st), 1) {
+		// Unreachable states sort last, regardless of the heuristic and km.
+		return Key{math.Inf(1), math.Inf(1)}
+	}
+	if s.bottleneck {
+		// The Dist heuristic does not bound bottleneck values, so plan
+		// without one.
+		m := math.Min(s.g.get(st), s.rhs.get(st))
+		return Key{m, m}
+	}
+	h := s.heuristic(s.start, st)
+	if s.g.get(st) >= s.rhs.get(st) {
+		// Only the keys of overconsistent (or consistent) states are inflated,
+		// underconsistent ones must be processed in optimal order for the
+		// inflation bound to hold.
+		h *= s.inflation()
+	}
+	a := math.Min(s.g.get(st), s.rhs.get(st)) + h + s.km
 	b := math.Min(s.g.get(st), s.rhs.get(st))
-	return key{a, b}
+	return Key{a, b}
 }
 
-func (s *Planner) updateVertex(u State) {
+func (s *PlannerOf[S]) updateVertex(u S) {
 	eq := float64Equals(s.g.get(u), s.rhs.get(u))
-	cont := s.u.contains(u)
+	cont := s.u.Contains(u)
 
+	s.stats.VertexUpdates++
+	action := VertexUnchanged
 	if !eq && cont {
-		s.u.update(u, s.calcKey(u))
+		s.u.Update(u, s.calcKey(u))
+		s.trace("update", u)
+		action = VertexUpdated
 	} else if !eq && !cont {
-		s.u.insert(u, s.calcKey(u))
+		s.u.Insert(u, s.calcKey(u))
+		s.stats.QueueInserts++
+		s.trace("insert", u)
+		action = VertexInserted
 	} else if eq && cont {
-		s.u.remove(u)
+		s.u.Remove(u)
+		s.stats.QueueRemoves++
+		s.trace("remove", u)
+		action = VertexRemoved
+	}
+	if s.onUpdateVertex != nil {
+		s.onUpdateVertex(u, action, s.calcKey(u))
 	}
 }
 
-func (s *Planner) computeShortestPath() {
-	for !s.u.isEmpty() && (s.u.topKey().compare(s.calcKey(s.start)) == -1 || s.rhs.get(s.start) > s.g.get(s.start)) {
-		u := s.u.top()
-		kOld := s.u.topKey()
-		kNew := s.calcKey(u)
+// VertexAction is the change that an UpdateVertex step of the algorithm made
+// to the priority queue, see OnUpdateVertex.
+type VertexAction int
 
-		if kOld.compare(kNew) == -1 {
-			s.u.update(u, kNew)
-		} else if s.g.get(u) > s.rhs.get(u) {
-			s.g[u] = s.rhs.get(u)
-			s.u.remove(u)
-			for _, st := range s.d.Pred(u) {
-				if !st.Equals(s.goal) {
-					s.rhs[st] = math.Min(s.rhs.get(st), s.d.Cost(st, u)+s.g.get(u))
-				}
+const (
+	// VertexUnchanged means the vertex is consistent and was not queued, so
+	// the queue was left unchanged.
+	VertexUnchanged VertexAction = iota
 
-				s.updateVertex(st)
+	// VertexInserted means the vertex became inconsistent and was inserted
+	// into the queue.
+	VertexInserted
+
+	// VertexUpdated means the vertex is still inconsistent and its key in
+	// the queue was updated.
+	VertexUpdated
+
+	// VertexRemoved means the vertex became consistent and was removed from
+	// the queue.
+	VertexRemoved
+)
+
+// String returns the name of the action, e.g. "insert".
+func (a VertexAction) String() string {
+	switch a {
+	case VertexUnchanged:
+		return "none"
+	case VertexInserted:
+		return "insert"
+	case VertexUpdated:
+		return "update"
+	case VertexRemoved:
+		return "remove"
+	}
+	return fmt.Sprintf("VertexAction(%d)", int(a))
+}
+
+// OnUpdateVertex sets a function to be called at the end of every UpdateVertex
+// step of the algorithm (as in the pseudocode of the paper), i.e. each time
+// the planner reconsiders whether a vertex belongs in the priority queue, with
+// the vertex, the change made to the queue and the current key of the vertex.
+// This may be used e.g. to animate the queue membership during a replan.
+// Passing nil removes the hook, which is the default.
+func (s *PlannerOf[S]) OnUpdateVertex(fn func(st S, action VertexAction, k Key)) {
+	s.onUpdateVertex = fn
+}
+
+// converged tells if the start state is consistent and no queued vertex has a
+// smaller key than it, i.e. the shortest path to the start is known.
+func (s *PlannerOf[S]) converged() bool {
+	if s.u.IsEmpty() {
+		return true
+	}
+	return s.u.TopKey().compare(s.calcKey(s.start)) != -1 && s.rhs.get(s.start) <= s.g.get(s.start)
+}
+
+// computeShortestPath expands vertices until the planner has converged. If ctx
+// is non-nil it is checked every cancelInterval expansions, and its error is
+// returned once it is done. Likewise ErrExpansionLimit and ErrNodeLimit are
+// returned once the respective limit is hit. In all cases the planner is left
+// in a consistent state, so a later call resumes the work.
+func (s *PlannerOf[S]) computeShortestPath(ctx context.Context) error {
+	for i := 0; !s.converged(); i++ {
+		if ctx != nil && i%s.cancelInterval() == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-		} else {
-			gOld := s.g.get(u)
-			s.g[u] = math.Inf(1)
-
-			preds := s.d.Pred(u)
-			preds = append(preds, u)
-
-			for _, st := range preds {
-				if float64Equals(s.rhs.get(st), s.d.Cost(st, u)+gOld) {
-					if !st.Equals(s.goal) {
-						minRhs := math.Inf(0)
-
-						for _, sPrime := range s.d.Succ(st) {
-							rhsPrime := s.d.Cost(st, sPrime) + s.g.get(sPrime)
-							if rhsPrime < minRhs {
-								minRhs = rhsPrime
-							}
-						}
+		}
+		if s.maxExpansions > 0 && s.expansions >= s.maxExpansions {
+			return ErrExpansionLimit
+		}
+		if s.maxNodes > 0 && s.nodes() > s.maxNodes {
+			return ErrNodeLimit
+		}
+		if s.onIteration != nil {
+			s.onIteration(s.calcKey(s.start), s.u.TopKey())
+		}
+		s.expand()
+	}
+	return nil
+}
+
+// OnIteration sets a function to be called once per iteration of the main
+// loop of the planner, before the top vertex is expanded, with the current key
+// of the start state and the smallest key in the priority queue. The planner
+// has converged once topKey is no longer less than startKey, so a visualizer
+// may use this to show the gap closing. Passing nil removes the hook, which
+// is the default and costs nothing.
+func (s *PlannerOf[S]) OnIteration(fn func(startKey, topKey Key)) {
+	s.onIteration = fn
+}
+
+// OnExpand sets a function to be called each time the planner pops a vertex
+// from the top of the priority queue, before the vertex is modified, with the
+// vertex and its key in the queue. Overconsistent tells if its g value is
+// greater than its rhs value (its cost-to-goal decreased); otherwise it is
+// underconsistent (its cost-to-goal increased), or its key merely became
+// outdated. This may be used to record and visualize the expansion order.
+// Passing nil removes the hook, which is the default.
+func (s *PlannerOf[S]) OnExpand(fn func(st S, k Key, overconsistent bool)) {
+	s.onExpand = fn
+}
 
-						s.rhs[st] = minRhs
+// expand processes the vertex with the smallest key in the priority queue,
+// which must not be empty.
+func (s *PlannerOf[S]) expand() {
+	u := s.u.Top()
+	s.trace("expand", u)
+	kOld := s.u.TopKey()
+	kNew := s.calcKey(u)
+	s.expansions++
+	if s.onExpand != nil {
+		s.onExpand(u, kOld, s.g.get(u) > s.rhs.get(u))
+	}
+
+	if kOld.compare(kNew) == -1 {
+		s.u.Update(u, kNew)
+		s.trace("update", u)
+	} else if s.g.get(u) > s.rhs.get(u) {
+		s.g[u] = s.rhs.get(u)
+		s.trace("g", u)
+		s.u.Remove(u)
+		s.stats.QueueRemoves++
+		s.trace("remove", u)
+		for _, st := range s.pred(u) {
+			if s.beyondLimit(st) {
+				continue
+			}
+			if !s.isGoal(st) {
+				s.rhs[st] = math.Min(s.rhs.get(st), s.combine(s.d.Cost(st, u), s.g.get(u)))
+				s.trace("rhs", st)
+			}
+
+			s.updateVertex(st)
+		}
+	} else {
+		gOld := s.g.get(u)
+		s.g[u] = math.Inf(1)
+		s.trace("g", u)
+
+		// The predecessors are copied, since the Data interface may share the
+		// slice, into a buffer that is reused across expansions.
+		s.preds = append(append(s.preds[:0], s.pred(u)...), u)
+
+		for _, st := range s.preds {
+			if s.beyondLimit(st) {
+				continue
+			}
+			if float64Equals(s.rhs.get(st), s.combine(s.d.Cost(st, u), gOld)) {
+				if !s.isGoal(st) {
+					minRhs := math.Inf(0)
+
+					for _, sPrime := range s.succ(st) {
+						rhsPrime := s.combine(s.d.Cost(st, sPrime), s.g.get(sPrime))
+						if rhsPrime < minRhs {
+							minRhs = rhsPrime
+						}
 					}
-				}
 
-				s.updateVertex(st)
+					s.rhs[st] = minRhs
+					s.trace("rhs", st)
+				}
 			}
+
+			s.updateVertex(st)
 		}
 	}
 }
 
 // FlagChanged indicates that the cost of traversal from state u to state v has
 // changed from cOld to cNew and needs to be replanned at the next iteration.
-func (s *Planner) FlagChanged(u, v State, cOld, cNew float64) {
+func (s *PlannerOf[S]) FlagChanged(u, v S, cOld, cNew float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flagRHS(u, v, cOld, cNew)
+	s.updateVertex(u)
+	s.dirty = true
+	if s.propLimit > 0 && !s.fromScratch {
+		s.changed = append(s.changed, u)
+	}
+}
+
+// flagRHS updates the rhs value of u for the change of the cost of traversal
+// from u to v from cOld to cNew, without updating its queue entry.
+func (s *PlannerOf[S]) flagRHS(u, v S, cOld, cNew float64) {
 	if cOld > cNew {
-		if !u.Equals(s.goal) {
-			s.rhs[u] = math.Min(s.rhs.get(u), cNew+s.g.get(v))
+		if !s.isGoal(u) {
+			s.rhs[u] = math.Min(s.rhs.get(u), s.combine(cNew, s.g.get(v)))
+			s.trace("rhs", u)
 		}
-	} else if float64Equals(s.rhs.get(u), cOld+s.g.get(v)) {
-		if !u.Equals(s.goal) {
+	} else if float64Equals(s.rhs.get(u), s.combine(cOld, s.g.get(v))) {
+		if !s.isGoal(u) {
 			minRhs := math.Inf(1)
 
-			for _, sPrime := range s.d.Succ(u) {
-				rhsPrime := s.d.Cost(u, sPrime) + s.g.get(sPrime)
+			for _, sPrime := range s.succ(u) {
+				rhsPrime := s.combine(s.d.Cost(u, sPrime), s.g.get(sPrime))
 				if rhsPrime < minRhs {
 					minRhs = rhsPrime
 				}
 			}
 
 			s.rhs[u] = minRhs
+			s.trace("rhs", u)
 		}
 	}
-
-	s.updateVertex(u)
 }
 
 // UpdateStart changes the start location post-initialization. Use this to
 // cheaply move along the path (I.e. this does not need to replan the entire
 // path).
-func (p *Planner) UpdateStart(s State) {
+func (p *PlannerOf[S]) UpdateStart(s S) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	oldStart := p.start
+	p.undoStart, p.undoKm, p.canUndo = oldStart, p.km, true
 	p.start = s
-	p.km += p.d.Dist(oldStart, s)
+	p.km += p.inflation() * p.heuristic(oldStart, s)
+	p.dirty = true
+	if p.maxKm > 0 && p.km > p.maxKm {
+		p.reinit()
+		p.reinits++
+		p.trace("reinit", p.start)
+	}
 }
 
 // Plan recomputes the lowest cost path through the map, taking into account
 // changes in start location and edge costs.
 //
-// If no path is found, nil is returned.
-func (s *Planner) Plan() (path []State) {
+// If no path is found, nil is returned. See PlanE for the reason. If the start
+// is a goal, the path consists of the start alone and its cost (see PathCost)
+// is zero.
+func (s *PlannerOf[S]) Plan() []S {
+	path, err := s.PlanE()
+	if err != nil {
+		return nil
+	}
+	return path
+}
+
+// ErrNoPath is returned by PlanE when the goal cannot be reached from the
+// start.
+var ErrNoPath = errors.New("dstarlite: no path")
+
+// PlanE is like Plan, except that it also returns an error describing why no
+// path could be found, such as ErrNoPath. If a path was found the error is
+// nil.
+func (s *PlannerOf[S]) PlanE() ([]S, error) {
+	return s.plan(nil, nil)
+}
+
+// PlanWrite is like PlanE, except that it also writes each state of the path
+// to w (formatted by the given function, one per line) as soon as the
+// gradient walk reaches it, which is useful for command line tools and for
+// debugging long searches. If writing fails, the write error is returned.
+func (s *PlannerOf[S]) PlanWrite(w io.Writer, format func(S) string) ([]S, error) {
+	return s.plan(nil, func(st S) error {
+		_, err := io.WriteString(w, format(st)+"\n")
+		return err
+	})
+}
+
+// plan implements PlanE, calling emit (if non-nil) with each state of the
+// path as the gradient walk produces it. If emit returns an error, planning
+// stops and that error is returned. The context, if non-nil, may cancel the
+// computation of the shortest path.
+func (s *PlannerOf[S]) plan(ctx context.Context, emit func(S) error) ([]S, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expansions = 0
+	s.stats = Stats{}
+	if !s.dirty && s.walked {
+		return s.cachedPlan(emit)
+	}
+	if s.dirty {
+		if err := s.computeShortestPath(ctx); err != nil {
+			return nil, err
+		}
+		s.dirty = false
+		s.changed = s.changed[:0]
+		if s.fromScratch || s.expansions > s.fullExpansions {
+			s.fullExpansions = s.expansions
+			s.fromScratch = false
+		}
+	}
+	var (
+		path []S
+		err  error
+	)
+	if s.bottleneck {
+		path = s.bottleneckWalk()
+		if path == nil {
+			err = ErrNoPath
+		} else if emit != nil {
+			for _, st := range path {
+				if err = emit(st); err != nil {
+					path = nil
+					break
+				}
+			}
+		}
+	} else {
+		path, err = s.walk(emit)
+	}
+	if err != nil {
+		s.record(nil)
+	} else {
+		s.record(path)
+	}
+	s.pathCost = math.Inf(1)
+	if err == nil && path != nil {
+		s.pathCost = sumCost(s.d, path)
+	}
+	s.stats.PathLength = len(path)
+
+	// Unless emit failed, the result holds until the planner changes.
+	s.walked = err == nil || err == ErrNoPath || err == ErrGradientCycle
+	s.walkPath, s.walkErr = nil, err
+	if path != nil {
+		s.walkPath = append([]S(nil), path...)
+	}
+	return path, err
+}
+
+// cachedPlan returns (a copy of) the result of the last gradient walk, which
+// is still valid as the planner did not change since, emitting its states like
+// the walk would.
+func (s *PlannerOf[S]) cachedPlan(emit func(S) error) ([]S, error) {
+	path, err := append([]S(nil), s.walkPath...), s.walkErr
+	if s.walkPath == nil {
+		path = nil
+	}
+	if emit != nil {
+		for _, st := range path {
+			if emitErr := emit(st); emitErr != nil {
+				return nil, emitErr
+			}
+		}
+	}
+	if err != nil {
+		s.record(nil)
+	} else {
+		s.record(path)
+	}
+	s.stats.PathLength = len(path)
+	return path, err
+}
+
+// next returns the successor of st through which the goal is reached at the
+// lowest cost, and false if none of its successors reaches the goal. Blended
+// planners break ties in favour of the successor closest to their attractors,
+// see NewBlended.
+func (s *PlannerOf[S]) next(st S) (next S, ok bool) {
+	return s.nextExcept(st, nil)
+}
+
+// nextExcept is like next, but never returns a state in the except set.
+func (s *PlannerOf[S]) nextExcept(st S, except map[S]bool) (next S, ok bool) {
+	minRhs := math.Inf(1)
+	var minS S
+
+	for _, sPrime := range s.succ(st) {
+		if except[sPrime] {
+			continue
+		}
+		rhsPrime := s.combine(s.d.Cost(st, sPrime), s.g.get(sPrime))
+		better := rhsPrime < minRhs
+		if s.blend != nil && rhsPrime == minRhs && !math.IsInf(minRhs, 1) {
+			better = s.blendDist(sPrime) < s.blendDist(minS)
+		}
+		if better {
+			minRhs = rhsPrime
+			minS = sPrime
+		}
+	}
+	return minS, !math.IsInf(minRhs, 1)
+}
+
+// walk follows the gradient of the computed g values from the start state to
+// the goal, returning the path (or ErrNoPath if there is none).
+//
+// Should the walk revisit a state (which can only happen due to zero cost
+// edges or floating point error) it is handled according to the cycle policy,
+// see SetCyclePolicy.
+//
+// If emit is non-nil it is called with each state as it is added to the path.
+func (s *PlannerOf[S]) walk(emit func(S) error) ([]S, error) {
 	st := s.start
-	path = append(path, st)
+	path := []S{st}
+	visited := map[S]bool{st: true}
+	if !s.isGoal(st) && math.IsInf(s.rhs.get(st), 0) {
+		// No path, so there is nothing to emit.
+		return nil, ErrNoPath
+	}
+	if emit != nil {
+		if err := emit(st); err != nil {
+			return nil, err
+		}
+	}
 
-	s.computeShortestPath()
-	for !st.Equals(s.goal) {
+	// If the start is a goal the loop is skipped entirely, and the path is the
+	// start alone. For a new planner computeShortestPath then also returns
+	// without expanding any vertex, since the start has the smallest possible
+	// key and an rhs value of zero.
+	for !s.isGoal(st) {
 		// If rhs(sStart) == Inf then there is no known path.
 		if math.IsInf(s.rhs.get(st), 0) {
-			return nil
+			return nil, ErrNoPath
 		}
 
-		succs := s.d.Succ(st)
-		minRhs := math.Inf(1)
-		var minS State
-
-		for _, sPrime := range succs {
-			rhsPrime := s.d.Cost(st, sPrime) + s.g.get(sPrime)
-			if rhsPrime < minRhs {
-				minRhs = rhsPrime
-				minS = sPrime
+		next, ok := s.next(st)
+		if !ok {
+			return nil, ErrNoPath
+		}
+		if visited[next] {
+			if s.cyclePolicy != CycleBestEffort {
+				return path, ErrGradientCycle
+			}
+			next, ok = s.nextExcept(st, visited)
+			if !ok {
+				return path, ErrGradientCycle
 			}
 		}
-
-		st = minS
+		visited[next] = true
+		st = next
 		path = append(path, st)
+		if emit != nil {
+			if err := emit(st); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	return path
+	return path, nil
 }
 
+// OptionOf configures a planner, see NewOf.
+type OptionOf[S comparable] func(s *PlannerOf[S])
+
+// Option configures a planner, see New.
+type Option = OptionOf[State]
+
 // Returns an new D* Lite Planner given the specified Data interface, start
-// and end goal states.
-func New(data Data, start, goal State) *Planner {
-	dsl := new(Planner)
-	dsl.d = data
-	dsl.rhs = make(valueMap)
-	dsl.g = make(valueMap)
-	dsl.u = newPriorityQueue()
+// and end goal states, with the given options applied. It panics if any of
+// data, start or goal is nil, see NewChecked.
+//
+// The start may equal the goal, in which case Plan returns the single state
+// path []State{start} (with a PathCost of zero) without expanding any vertex.
+func New(data Data, start, goal State, opts ...Option) *Planner {
+	if err := checkNew(data, start, goal); err != nil {
+		panic(err)
+	}
+	return NewOf(data, start, goal, opts...)
+}
 
-	dsl.start = start
+// NewOf is like New, except that it returns a planner for states of the
+// comparable type S, e.g. a struct{X, Y int}, which need no Equals method. It
+// panics if data is nil.
+func NewOf[S comparable](data DataOf[S], start, goal S, opts ...OptionOf[S]) *PlannerOf[S] {
+	if data == nil {
+		panic(errors.New("dstarlite: nil Data"))
+	}
+	dsl := newPlanner(data, start, opts...)
 	dsl.goal = goal
+	dsl.goals[goal] = 0
 	dsl.rhs[goal] = 0.0
 
-	k := key{dsl.d.Dist(start, goal), 0}
-	dsl.u.insert(goal, k)
+	k := Key{dsl.heuristic(start, goal), 0}
+	dsl.u.Insert(goal, k)
+	return dsl
+}
+
+// newPlanner returns a new planner without any goals, with the given options
+// applied.
+func newPlanner[S comparable](data DataOf[S], start S, opts ...OptionOf[S]) *PlannerOf[S] {
+	dsl := new(PlannerOf[S])
+	dsl.mu = new(sync.RWMutex)
+	for _, opt := range opts {
+		opt(dsl)
+	}
+	dsl.equals = equalsFunc[S]()
+	dsl.d = data
+	dsl.rhs = make(valueMap[S])
+	dsl.g = make(valueMap[S])
+	dsl.goals = make(valueMap[S])
+	dsl.u = dsl.newQueue()
+	dsl.start = start
+	dsl.dirty = true
+	dsl.fromScratch = true
+	dsl.pathCost = math.Inf(1)
 	return dsl
 }
+
+// NewChecked is like New, except that it returns an error instead of panicking
+// if any of data, start or goal is nil.
+func NewChecked(data Data, start, goal State, opts ...Option) (*Planner, error) {
+	if err := checkNew(data, start, goal); err != nil {
+		return nil, err
+	}
+	return New(data, start, goal, opts...), nil
+}
+
+// checkNew validates the arguments of New.
+func checkNew(data Data, start, goal State) error {
+	switch {
+	case data == nil:
+		return errors.New("dstarlite: nil Data")
+	case start == nil:
+		return errors.New("dstarlite: nil start state")
+	case goal == nil:
+		return errors.New("dstarlite: nil goal state")
+	}
+	return nil
+}
+
+// equalsFunc returns a function calling State.Equals if S is the State
+// interface, and nil otherwise.
+func equalsFunc[S comparable]() func(a, b S) bool {
+	var zero S
+	if _, ok := any(&zero).(*State); !ok {
+		return nil
+	}
+	return func(a, b S) bool {
+		x, _ := any(a).(State)
+		y, _ := any(b).(State)
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Equals(y)
+	}
+}
+
+// eq tells if the two states are equal: for a Planner according to
+// State.Equals (such that e.g. distinct pointers to equal states compare
+// equal), and according to == for any other PlannerOf.
+func (s *PlannerOf[S]) eq(a, b S) bool {
+	if s.equals == nil {
+		return a == b
+	}
+	return s.equals(a, b)
+}
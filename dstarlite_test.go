@@ -0,0 +1,987 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// parseGrid returns the grid of a text map with one line per row, in which
+// '#' marks blocked cells and 'S' and 'G' the start and goal cells. Leading
+// and trailing newlines are ignored.
+func parseGrid(m string) (g *grid.Grid, start, goal grid.Coord) {
+	rows := strings.Split(strings.Trim(m, "\n"), "\n")
+	g = grid.New(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, r := range row {
+			c := grid.Coord{X: x, Y: y}
+			switch r {
+			case '#':
+				g.SetBlocked(c, true)
+			case 'S':
+				start = c
+			case 'G':
+				goal = c
+			}
+		}
+	}
+	return g, start, goal
+}
+
+// costsEqual tells if two path costs are equal, up to the floating point error
+// of summing their edge costs in a different order.
+func costsEqual(a, b float64) bool {
+	return a == b || math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// maze returns a grid of size by size cells (size must be odd) holding a maze
+// generated by a randomized depth-first search seeded by seed. Its passages
+// connect every cell with even coordinates, in particular the corners.
+func maze(size int, seed int64) *grid.Grid {
+	g := grid.New(size, size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			g.SetBlocked(grid.Coord{X: x, Y: y}, true)
+		}
+	}
+	r := rand.New(rand.NewSource(seed))
+	g.SetBlocked(grid.Coord{X: 0, Y: 0}, false)
+	stack := []grid.Coord{{X: 0, Y: 0}}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		var next []grid.Coord
+		for _, d := range [...]grid.Coord{{X: 1}, {Y: 1}, {X: -1}, {Y: -1}} {
+			n := grid.Coord{X: c.X + 2*d.X, Y: c.Y + 2*d.Y}
+			if g.InBounds(n) && g.Blocked(n) {
+				next = append(next, n)
+			}
+		}
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		n := next[r.Intn(len(next))]
+		g.SetBlocked(grid.Coord{X: (c.X + n.X) / 2, Y: (c.Y + n.Y) / 2}, false)
+		g.SetBlocked(n, false)
+		stack = append(stack, n)
+	}
+	return g
+}
+
+// node is a state of a graph.
+type node string
+
+func (n node) Equals(other dstarlite.State) bool {
+	o, ok := other.(node)
+	return ok && o == n
+}
+
+// graph is a Data interface for a small directed graph, given by the cost of
+// each of its edges. Its Dist is zero.
+type graph map[[2]node]float64
+
+func (g graph) Succ(s dstarlite.State) []dstarlite.State {
+	var succ []dstarlite.State
+	for e := range g {
+		if e[0] == s {
+			succ = append(succ, e[1])
+		}
+	}
+	return succ
+}
+
+func (g graph) Pred(s dstarlite.State) []dstarlite.State {
+	var pred []dstarlite.State
+	for e := range g {
+		if e[1] == s {
+			pred = append(pred, e[0])
+		}
+	}
+	return pred
+}
+
+func (g graph) Dist(a, b dstarlite.State) float64 { return 0 }
+
+func (g graph) Cost(a, b dstarlite.State) float64 {
+	if c, ok := g[[2]node{a.(node), b.(node)}]; ok {
+		return c
+	}
+	return math.Inf(1)
+}
+
+// nodes converts a path of nodes to states.
+func nodes(path ...node) []dstarlite.State {
+	states := make([]dstarlite.State, len(path))
+	for i, n := range path {
+		states[i] = n
+	}
+	return states
+}
+
+// setBlocked marks the cell c of g as blocked or passable, and flags the
+// edges whose cost changed to each of the planners.
+func setBlocked(g *grid.Grid, c grid.Coord, blocked bool, planners ...*dstarlite.Planner) {
+	type edge struct {
+		u, v dstarlite.State
+		cost float64
+	}
+	var edges []edge
+	for _, n := range g.Succ(c) {
+		edges = append(edges, edge{c, n, g.Cost(c, n)}, edge{n, c, g.Cost(n, c)})
+	}
+	g.SetBlocked(c, blocked)
+	for _, p := range planners {
+		for _, e := range edges {
+			p.FlagChanged(e.u, e.v, e.cost, g.Cost(e.u, e.v))
+		}
+	}
+}
+
+func TestPlanSkipsComputeWhenClean(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(g *grid.Grid, p *dstarlite.Planner)
+	}{
+		{"no change", nil},
+		{"cell blocked on path", func(g *grid.Grid, p *dstarlite.Planner) {
+			setBlocked(g, grid.Coord{X: 2, Y: 0}, true, p)
+		}},
+		{"cell blocked off path", func(g *grid.Grid, p *dstarlite.Planner) {
+			setBlocked(g, grid.Coord{X: 0, Y: 4}, true, p)
+		}},
+		{"start moved", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.UpdateStart(grid.Coord{X: 1, Y: 0})
+		}},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(`
+S....
+.###.
+.....
+.###.
+....G
+`)
+		p := dstarlite.New(g, start, goal)
+		if !p.Dirty() {
+			t.Fatalf("%s: new planner is not dirty", tst.name)
+		}
+		p.Plan()
+		if tst.change != nil {
+			tst.change(g, p)
+			if !p.Dirty() {
+				t.Errorf("%s: planner is not dirty after the change", tst.name)
+			}
+			p.Plan()
+		}
+		if p.Dirty() {
+			t.Errorf("%s: planner is dirty after Plan", tst.name)
+		}
+		want := p.Plan()
+		path := p.Plan()
+		if n := p.LastExpansions(); n != 0 {
+			t.Errorf("%s: Plan without changes expanded %d vertices, want 0", tst.name, n)
+		}
+		if p.Dirty() {
+			t.Errorf("%s: planner is dirty after Plan without changes", tst.name)
+		}
+		if !reflect.DeepEqual(path, want) {
+			t.Errorf("%s: Plan without changes returned %v, want %v", tst.name, path, want)
+		}
+	}
+}
+
+func TestPlanCached(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(g *grid.Grid, p *dstarlite.Planner)
+		walk   bool // Whether the next call to Plan walks the path again.
+	}{
+		{"no change", func(g *grid.Grid, p *dstarlite.Planner) {}, false},
+		{"cell blocked off path", func(g *grid.Grid, p *dstarlite.Planner) {
+			setBlocked(g, grid.Coord{X: 0, Y: 4}, true, p)
+		}, true},
+		{"start moved", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.UpdateStart(grid.Coord{X: 1, Y: 0})
+		}, true},
+		{"successor order", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.SetSuccessorOrder(nil)
+		}, true},
+		{"cycle policy", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.SetCyclePolicy(dstarlite.CycleError)
+		}, true},
+		{"cost quantum", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.SetCostQuantum(0)
+		}, true},
+		{"epsilon", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.SetEpsilon(1)
+		}, true},
+		{"precompute", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.Precompute()
+		}, true},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(`
+S....
+.###.
+.....
+.###.
+....G
+`)
+		d := &countingData{Data: g}
+		p := dstarlite.New(d, start, goal)
+		p.Plan()
+		tst.change(g, p)
+		d.costs = 0
+		path := p.Plan()
+		if walked := d.costs > 0; walked != tst.walk {
+			t.Errorf("%s: Plan walked the path: %v, want %v", tst.name, walked, tst.walk)
+		}
+
+		// The cached path is returned as a copy, which the caller may modify.
+		want := append([]dstarlite.State(nil), path...)
+		path[0] = goal
+		d.costs = 0
+		if path := p.Plan(); !reflect.DeepEqual(path, want) || d.costs > 0 {
+			t.Errorf("%s: Plan without changes returned %v after %d Cost calls, want %v after none", tst.name, path, d.costs, want)
+		}
+	}
+
+	// Failures to find a path are cached too.
+	g, start, goal := parseGrid(`
+S#G
+`)
+	p := dstarlite.New(g, start, goal)
+	for i := 0; i < 2; i++ {
+		if path, err := p.PlanE(); path != nil || err != dstarlite.ErrNoPath {
+			t.Errorf("call %d: PlanE returned %v, %v, want nil, ErrNoPath", i, path, err)
+		}
+	}
+}
+
+func TestGAndRHS(t *testing.T) {
+	g, start, goal := parseGrid(`
+S.G
+###
+...
+`)
+	p := dstarlite.New(g, start, goal)
+	if v := p.G(start); !math.IsInf(v, 1) {
+		t.Errorf("G(start) = %v before Plan, want +Inf", v)
+	}
+	p.Plan()
+
+	// Planning may stop with the start overconsistent, before its g value is
+	// lowered to match its rhs value.
+	if v := p.RHS(start); v != 2 {
+		t.Errorf("RHS(start) = %v, want 2", v)
+	}
+	tests := []struct {
+		c      grid.Coord
+		g, rhs float64
+	}{
+		{grid.Coord{X: 1, Y: 0}, 1, 1},
+		{grid.Coord{X: 2, Y: 0}, 0, 0},
+		{grid.Coord{X: 0, Y: 2}, math.Inf(1), math.Inf(1)}, // Unreachable.
+	}
+	for _, tst := range tests {
+		if v := p.G(tst.c); v != tst.g {
+			t.Errorf("G(%v) = %v, want %v", tst.c, v, tst.g)
+		}
+		if v := p.RHS(tst.c); v != tst.rhs {
+			t.Errorf("RHS(%v) = %v, want %v", tst.c, v, tst.rhs)
+		}
+	}
+}
+
+func TestBlockedGoalKeysNotNaN(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     string
+		block *grid.Coord // A cell to block before planning, if any.
+		move  grid.Coord  // Where the start moves after the first plan.
+	}{
+		{"goal walled in", `
+S....
+...#.
+..#G#
+...#.
+`, nil, grid.Coord{X: 1, Y: 0}},
+		{"start walled in", `
+.#...
+#S#..
+.#..G
+`, nil, grid.Coord{X: 0, Y: 0}},
+		{"goal in wall", `
+S...
+....
+...G
+`, &grid.Coord{X: 3, Y: 2}, grid.Coord{X: 0, Y: 1}},
+	}
+	checkKeys := func(name, when string, p *dstarlite.Planner) {
+		for _, item := range p.QueueSnapshot() {
+			if math.IsNaN(item.Key.A) || math.IsNaN(item.Key.B) {
+				t.Errorf("%s: key of %v is %v %s", name, item.State, item.Key, when)
+			}
+		}
+		if math.IsNaN(p.RHS(p.Start())) || math.IsNaN(p.Km()) {
+			t.Errorf("%s: RHS(start) = %v, Km() = %v %s", name, p.RHS(p.Start()), p.Km(), when)
+		}
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		if tst.block != nil {
+			g.SetBlocked(*tst.block, true)
+		}
+		p := dstarlite.New(g, start, goal)
+		g.SetPlanner(p)
+		if _, err := p.PlanE(); err != dstarlite.ErrNoPath {
+			t.Errorf("%s: got error %v, want ErrNoPath", tst.name, err)
+		}
+		checkKeys(tst.name, "after Plan", p)
+
+		// Moving the start grows km, and toggling a cell flags edges between
+		// states of infinite cost.
+		p.UpdateStart(tst.move)
+		c := grid.Coord{X: 2, Y: 0}
+		g.SetBlocked(c, !g.Blocked(c))
+		if _, err := p.PlanE(); err != dstarlite.ErrNoPath {
+			t.Errorf("%s: got error %v after the changes, want ErrNoPath", tst.name, err)
+		}
+		checkKeys(tst.name, "after replanning", p)
+		p.Precompute()
+		checkKeys(tst.name, "after Precompute", p)
+	}
+}
+
+// Successor orderings of grid cells, for SetSuccessorOrder.
+var successorOrders = []struct {
+	name  string
+	order func(s dstarlite.State, succ []dstarlite.State)
+}{
+	{"data", nil},
+	{"reversed", func(s dstarlite.State, succ []dstarlite.State) {
+		for i, j := 0, len(succ)-1; i < j; i, j = i+1, j-1 {
+			succ[i], succ[j] = succ[j], succ[i]
+		}
+	}},
+	{"row-major", func(s dstarlite.State, succ []dstarlite.State) {
+		sortCells(succ, func(a, b grid.Coord) bool {
+			return a.Y < b.Y || a.Y == b.Y && a.X < b.X
+		})
+	}},
+	{"column-major", func(s dstarlite.State, succ []dstarlite.State) {
+		sortCells(succ, func(a, b grid.Coord) bool {
+			return a.X < b.X || a.X == b.X && a.Y < b.Y
+		})
+	}},
+}
+
+// sortCells stably sorts a few grid cells in place, without allocating.
+func sortCells(cells []dstarlite.State, less func(a, b grid.Coord) bool) {
+	for i := 1; i < len(cells); i++ {
+		for j := i; j > 0 && less(cells[j].(grid.Coord), cells[j-1].(grid.Coord)); j-- {
+			cells[j], cells[j-1] = cells[j-1], cells[j]
+		}
+	}
+}
+
+func TestSuccessorOrderPathCost(t *testing.T) {
+	want := math.Inf(1)
+	for _, o := range successorOrders {
+		g := maze(31, 7)
+		// Open up the maze, such that many equal-cost paths exist.
+		for y := 1; y < 31; y += 4 {
+			for x := 0; x < 31; x++ {
+				g.SetBlocked(grid.Coord{X: x, Y: y}, false)
+			}
+		}
+		p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 30, Y: 30})
+		p.SetSuccessorOrder(o.order)
+		path := p.Plan()
+		if path == nil {
+			t.Fatalf("%s order: no path found", o.name)
+		}
+		cost := 0.0
+		for i := 1; i < len(path); i++ {
+			cost += g.Cost(path[i-1], path[i])
+		}
+		if o.order == nil {
+			want = cost
+		} else if !costsEqual(cost, want) {
+			t.Errorf("%s order: path cost %v, want %v", o.name, cost, want)
+		}
+	}
+}
+
+// The benchmarks below plan across a large open grid with successors in the
+// order of the grid, in reverse order, and in row-major (memory) order, to
+// measure the effect of the order on the locality of the search.
+
+func benchmarkSuccessorOrder(b *testing.B, order func(s dstarlite.State, succ []dstarlite.State)) {
+	g := grid.New(128, 128)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 127, Y: 127})
+		p.SetSuccessorOrder(order)
+		p.Plan()
+	}
+}
+
+func BenchmarkSuccessorOrderData(b *testing.B) {
+	benchmarkSuccessorOrder(b, successorOrders[0].order)
+}
+
+func BenchmarkSuccessorOrderReversed(b *testing.B) {
+	benchmarkSuccessorOrder(b, successorOrders[1].order)
+}
+
+func BenchmarkSuccessorOrderRowMajor(b *testing.B) {
+	benchmarkSuccessorOrder(b, successorOrders[2].order)
+}
+
+// diagonalGrid is an 8-connected view of a grid, in which diagonal moves cost
+// sqrt(2) and may not cut the corners of blocked cells.
+type diagonalGrid struct {
+	*grid.Grid
+}
+
+func (d diagonalGrid) neighbors(s dstarlite.State) []dstarlite.State {
+	c := s.(grid.Coord)
+	var n []dstarlite.State
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			nc := grid.Coord{X: c.X + dx, Y: c.Y + dy}
+			if nc != c && d.InBounds(nc) {
+				n = append(n, nc)
+			}
+		}
+	}
+	return n
+}
+
+func (d diagonalGrid) Succ(s dstarlite.State) []dstarlite.State { return d.neighbors(s) }
+func (d diagonalGrid) Pred(s dstarlite.State) []dstarlite.State { return d.neighbors(s) }
+
+func (d diagonalGrid) Dist(a, b dstarlite.State) float64 {
+	ac, bc := a.(grid.Coord), b.(grid.Coord)
+	dx, dy := math.Abs(float64(ac.X-bc.X)), math.Abs(float64(ac.Y-bc.Y))
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+func (d diagonalGrid) Cost(a, b dstarlite.State) float64 {
+	ac, bc := a.(grid.Coord), b.(grid.Coord)
+	if d.Blocked(ac) || d.Blocked(bc) {
+		return math.Inf(1)
+	}
+	if ac.X == bc.X || ac.Y == bc.Y {
+		return 1
+	}
+	if d.Blocked(grid.Coord{X: ac.X, Y: bc.Y}) || d.Blocked(grid.Coord{X: bc.X, Y: ac.Y}) {
+		return math.Inf(1)
+	}
+	return math.Sqrt2
+}
+
+// setBlocked marks the cell c as blocked or passable, and flags the edges
+// whose cost changed (including those cutting the corners of c) to each of
+// the planners.
+func (d diagonalGrid) setBlocked(c grid.Coord, blocked bool, planners ...*dstarlite.Planner) {
+	type edge struct {
+		u, v dstarlite.State
+		cost float64
+	}
+	var edges []edge
+	for _, u := range append(d.neighbors(c), c) {
+		for _, v := range d.neighbors(u) {
+			edges = append(edges, edge{u, v, d.Cost(u, v)})
+		}
+	}
+	d.SetBlocked(c, blocked)
+	for _, p := range planners {
+		for _, e := range edges {
+			if cost := d.Cost(e.u, e.v); cost != e.cost {
+				p.FlagChanged(e.u, e.v, e.cost, cost)
+			}
+		}
+	}
+}
+
+// replanField precomputes the distance field of an open 8-connected grid with
+// scattered obstacles, then blocks twenty random cells one by one and updates
+// the field after each. It returns the final path and the number of vertices
+// expanded by the updates.
+func replanField(seed int64, quantum float64) (d diagonalGrid, path []dstarlite.State, expansions int) {
+	r := rand.New(rand.NewSource(seed))
+	d = diagonalGrid{grid.New(40, 40)}
+	for i := 0; i < 160; i++ {
+		d.SetBlocked(grid.Coord{X: r.Intn(40), Y: r.Intn(40)}, true)
+	}
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 39, Y: 39}
+	d.SetBlocked(start, false)
+	d.SetBlocked(goal, false)
+	p := dstarlite.New(d, start, goal)
+	p.SetCostQuantum(quantum)
+	p.Precompute()
+	initial := p.LastExpansions()
+	for i := 0; i < 20; i++ {
+		c := grid.Coord{X: r.Intn(40), Y: r.Intn(40)}
+		if c != start && c != goal {
+			d.setBlocked(c, true, p)
+		}
+		p.Precompute()
+	}
+	expansions = p.LastExpansions() - initial
+	return d, p.Plan(), expansions
+}
+
+func TestCostQuantum(t *testing.T) {
+	pathCost := func(d diagonalGrid, path []dstarlite.State) float64 {
+		cost := 0.0
+		for i := 1; i < len(path); i++ {
+			cost += d.Cost(path[i-1], path[i])
+		}
+		return cost
+	}
+	var n, nQuantized int
+	for seed := int64(1); seed <= 10; seed++ {
+		d, want, ops := replanField(seed, 0)
+		dq, path, opsQuantized := replanField(seed, 1.0/(1<<30))
+		if (path == nil) != (want == nil) || !costsEqual(pathCost(dq, path), pathCost(d, want)) {
+			t.Errorf("seed %d: quantized path %v, want one of equal cost to %v", seed, path, want)
+		}
+		n += ops
+		nQuantized += opsQuantized
+	}
+	if nQuantized >= n {
+		t.Errorf("quantization did not reduce expansions (%d with, %d without)", nQuantized, n)
+	}
+}
+
+func TestOnIterationGapCloses(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		g := maze(21, seed)
+		p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20})
+		var gaps []float64
+		p.OnIteration(func(startKey, topKey dstarlite.Key) {
+			if startKey.A < topKey.A || startKey.A == topKey.A && startKey.B <= topKey.B {
+				t.Errorf("seed %d: iteration with top key %v not less than start key %v", seed, topKey, startKey)
+			}
+			gaps = append(gaps, startKey.A-topKey.A)
+		})
+		if p.Plan() == nil {
+			t.Fatalf("seed %d: no path found", seed)
+		}
+		if len(gaps) != p.LastExpansions() {
+			t.Errorf("seed %d: hook called %d times for %d expansions", seed, len(gaps), p.LastExpansions())
+		}
+		for i := 1; i < len(gaps); i++ {
+			if gaps[i] > gaps[i-1] {
+				t.Errorf("seed %d: gap grew from %v to %v at iteration %d", seed, gaps[i-1], gaps[i], i)
+				break
+			}
+		}
+		if !math.IsInf(gaps[0], 1) {
+			t.Errorf("seed %d: first gap is %v, want +Inf while the start is unknown", seed, gaps[0])
+		}
+	}
+}
+
+func TestOnUpdateVertex(t *testing.T) {
+	type event struct {
+		st     dstarlite.State
+		action dstarlite.VertexAction
+		k      dstarlite.Key
+	}
+	g, start, goal := parseGrid("S.G")
+	p := dstarlite.New(g, start, goal)
+	var events []event
+	p.OnUpdateVertex(func(st dstarlite.State, action dstarlite.VertexAction, k dstarlite.Key) {
+		events = append(events, event{st, action, k})
+	})
+	p.Plan()
+	want := []event{
+		{grid.Coord{X: 1, Y: 0}, dstarlite.VertexInserted, dstarlite.Key{A: 2, B: 1}},
+		{grid.Coord{X: 2, Y: 0}, dstarlite.VertexUnchanged, dstarlite.Key{A: 2, B: 0}},
+		{grid.Coord{X: 0, Y: 0}, dstarlite.VertexInserted, dstarlite.Key{A: 2, B: 2}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %v, want %v", events, want)
+	}
+
+	// On a replan every UpdateVertex step is reported, with the queue
+	// changes counted by Stats.
+	m := maze(21, 1)
+	p = dstarlite.New(m, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20})
+	p.Plan()
+	setBlocked(m, grid.Coord{X: 10, Y: 0}, true, p)
+	setBlocked(m, grid.Coord{X: 0, Y: 10}, true, p)
+	counts := make(map[dstarlite.VertexAction]int)
+	p.OnUpdateVertex(func(st dstarlite.State, action dstarlite.VertexAction, k dstarlite.Key) {
+		counts[action]++
+	})
+	p.Plan()
+	stats := p.Stats()
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total != stats.VertexUpdates {
+		t.Errorf("hook called %d times for %d vertex updates", total, stats.VertexUpdates)
+	}
+	if counts[dstarlite.VertexInserted] != stats.QueueInserts {
+		t.Errorf("hook reported %d inserts, want %d", counts[dstarlite.VertexInserted], stats.QueueInserts)
+	}
+	if counts[dstarlite.VertexUpdated] == 0 || counts[dstarlite.VertexRemoved] == 0 {
+		t.Errorf("got actions %v, want updates and removals as well", counts)
+	}
+	if s := dstarlite.VertexAction(7).String(); s != "VertexAction(7)" {
+		t.Errorf("got %q for an unknown action", s)
+	}
+}
+
+// failingWriter fails every write after the first n.
+type failingWriter struct {
+	n int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errWrite
+	}
+	w.n--
+	return len(b), nil
+}
+
+func TestPlanWrite(t *testing.T) {
+	format := func(s dstarlite.State) string {
+		c := s.(grid.Coord)
+		return fmt.Sprintf("%d,%d", c.X, c.Y)
+	}
+	tests := []struct {
+		name   string
+		m      string
+		repeat bool // Whether to write the (cached) path a second time.
+		states int  // Of the path, zero if there is none.
+		err    error
+	}{
+		{"path", "S.#\n..#\n#.G", false, 5, nil},
+		{"cached path", "S.#\n..#\n#.G", true, 5, nil},
+		{"no path", "S#G", false, 0, dstarlite.ErrNoPath},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		p := dstarlite.New(g, start, goal)
+		var buf bytes.Buffer
+		path, err := p.PlanWrite(&buf, format)
+		if tst.repeat {
+			buf.Reset()
+			path, err = p.PlanWrite(&buf, format)
+		}
+		if err != tst.err {
+			t.Errorf("%s: got error %v, want %v", tst.name, err, tst.err)
+		}
+		if len(path) != tst.states {
+			t.Errorf("%s: got path %v, want %d states", tst.name, path, tst.states)
+		}
+		var want string
+		for _, s := range path {
+			want += format(s) + "\n"
+		}
+		if buf.String() != want {
+			t.Errorf("%s: wrote %q, want %q", tst.name, buf.String(), want)
+		}
+	}
+}
+
+func TestPlanWriteError(t *testing.T) {
+	g, start, goal := parseGrid("S...G")
+	p := dstarlite.New(g, start, goal)
+	path, err := p.PlanWrite(&failingWriter{n: 2}, func(s dstarlite.State) string {
+		return fmt.Sprint(s)
+	})
+	if err != errWrite || path != nil {
+		t.Errorf("got path %v and error %v, want nil and %v", path, err, errWrite)
+	}
+}
+
+// cell is a state of an open, 4-connected grid, usable both directly (by
+// PlannerOf) and as a State (by Planner).
+type cell struct {
+	X, Y int
+}
+
+func (c cell) Equals(other dstarlite.State) bool {
+	o, ok := other.(cell)
+	return ok && o == c
+}
+
+// openGrid is the DataOf for an open grid of size by size cells.
+type openGrid struct {
+	size int
+}
+
+func (g openGrid) neighbors(c cell) []cell {
+	n := make([]cell, 0, 4)
+	for _, d := range [...]cell{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+		nc := cell{c.X + d.X, c.Y + d.Y}
+		if nc.X >= 0 && nc.Y >= 0 && nc.X < g.size && nc.Y < g.size {
+			n = append(n, nc)
+		}
+	}
+	return n
+}
+
+func (g openGrid) Succ(c cell) []cell     { return g.neighbors(c) }
+func (g openGrid) Pred(c cell) []cell     { return g.neighbors(c) }
+func (g openGrid) Cost(a, b cell) float64 { return 1 }
+
+func (g openGrid) Dist(a, b cell) float64 {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	return float64(dx + dy)
+}
+
+// stateGrid is the same grid as openGrid, as Data for Planner.
+type stateGrid struct {
+	openGrid
+}
+
+func (g stateGrid) states(cells []cell) []dstarlite.State {
+	s := make([]dstarlite.State, len(cells))
+	for i, c := range cells {
+		s[i] = c
+	}
+	return s
+}
+
+func (g stateGrid) Succ(s dstarlite.State) []dstarlite.State {
+	return g.states(g.neighbors(s.(cell)))
+}
+
+func (g stateGrid) Pred(s dstarlite.State) []dstarlite.State {
+	return g.states(g.neighbors(s.(cell)))
+}
+
+func (g stateGrid) Cost(a, b dstarlite.State) float64 { return 1 }
+
+func (g stateGrid) Dist(a, b dstarlite.State) float64 {
+	return g.openGrid.Dist(a.(cell), b.(cell))
+}
+
+func TestPlannerOfMatchesPlanner(t *testing.T) {
+	for _, size := range []int{1, 2, 5, 16} {
+		goal := cell{size - 1, size - 1}
+		p := dstarlite.New(stateGrid{openGrid{size}}, cell{}, goal)
+		q := dstarlite.NewOf[cell](openGrid{size}, cell{}, goal)
+		path, pathOf := p.Plan(), q.Plan()
+		if len(path) != len(pathOf) {
+			t.Fatalf("size %d: Planner path has %d states, PlannerOf %d", size, len(path), len(pathOf))
+		}
+		for i := range path {
+			if path[i] != pathOf[i] {
+				t.Fatalf("size %d: paths differ at %d: %v != %v", size, i, path[i], pathOf[i])
+			}
+		}
+		if want := float64(2 * (size - 1)); q.PathCost() != want {
+			t.Fatalf("size %d: PathCost() = %v, want %v", size, q.PathCost(), want)
+		}
+	}
+}
+
+// The benchmarks below plan across the same open grid, with states boxed in
+// the State interface (Planner) and used directly (PlannerOf), to compare the
+// allocations made by computeShortestPath.
+
+func BenchmarkPlan(b *testing.B) {
+	d := stateGrid{openGrid{64}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dstarlite.New(d, cell{}, cell{63, 63}).Plan()
+	}
+}
+
+func BenchmarkPlanOf(b *testing.B) {
+	d := openGrid{64}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dstarlite.NewOf[cell](d, cell{}, cell{63, 63}).Plan()
+	}
+}
+
+// place is a state compared by name, such that distinct pointers to places of
+// the same name are equal.
+type place struct {
+	name node
+}
+
+func (p *place) Equals(other dstarlite.State) bool {
+	o, ok := other.(*place)
+	return ok && o.name == p.name
+}
+
+// placeGraph is a graph whose states are a single pointer to a place per
+// node.
+type placeGraph struct {
+	g      graph
+	places map[node]*place
+}
+
+func newPlaceGraph(g graph) placeGraph {
+	pg := placeGraph{g, make(map[node]*place)}
+	for e := range g {
+		for _, n := range e {
+			pg.places[n] = &place{n}
+		}
+	}
+	return pg
+}
+
+func (pg placeGraph) states(nodes []dstarlite.State) []dstarlite.State {
+	for i, n := range nodes {
+		nodes[i] = pg.places[n.(node)]
+	}
+	return nodes
+}
+
+func (pg placeGraph) Succ(s dstarlite.State) []dstarlite.State {
+	return pg.states(pg.g.Succ(s.(*place).name))
+}
+
+func (pg placeGraph) Pred(s dstarlite.State) []dstarlite.State {
+	return pg.states(pg.g.Pred(s.(*place).name))
+}
+
+func (pg placeGraph) Dist(a, b dstarlite.State) float64 { return 0 }
+
+func (pg placeGraph) Cost(a, b dstarlite.State) float64 {
+	return pg.g.Cost(a.(*place).name, b.(*place).name)
+}
+
+func TestPlannerUsesEquals(t *testing.T) {
+	pg := newPlaceGraph(graph{
+		{"a", "b"}: 1,
+		{"b", "c"}: 1,
+		{"a", "d"}: 5,
+	})
+	p := dstarlite.New(pg, pg.places["a"], pg.places["c"])
+	p.AddGoal(pg.places["d"])
+	if path := p.Plan(); len(path) != 3 {
+		t.Fatalf("got path %v, want a, b, c", path)
+	}
+
+	// A distinct pointer to the goal is the goal already.
+	p.UpdateGoal(&place{"c"})
+	if p.Goal() != pg.places["c"] || p.Dirty() {
+		t.Errorf("UpdateGoal to an equal state moved the goal to %v", p.Goal())
+	}
+
+	// Removing a distinct pointer to the goal removes the goal.
+	p.RemoveGoal(&place{"c"})
+	want := []dstarlite.State{pg.places["a"], pg.places["d"]}
+	if path := p.Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("after RemoveGoal got path %v, want %v", path, want)
+	}
+}
+
+func TestNewChecked(t *testing.T) {
+	g := graph{{"a", "b"}: 1}
+	tests := []struct {
+		name        string
+		data        dstarlite.Data
+		start, goal dstarlite.State
+		err         string
+	}{
+		{"valid", g, node("a"), node("b"), ""},
+		{"nil data", nil, node("a"), node("b"), "dstarlite: nil Data"},
+		{"nil start", g, nil, node("b"), "dstarlite: nil start state"},
+		{"nil goal", g, node("a"), nil, "dstarlite: nil goal state"},
+	}
+	for _, tst := range tests {
+		p, err := dstarlite.NewChecked(tst.data, tst.start, tst.goal)
+		if tst.err == "" {
+			if err != nil || p == nil {
+				t.Errorf("%s: got %v, %v; want a planner", tst.name, p, err)
+			}
+			continue
+		}
+		if p != nil || err == nil || err.Error() != tst.err {
+			t.Errorf("%s: got %v, %v; want error %q", tst.name, p, err, tst.err)
+		}
+
+		// New panics with the same error.
+		func() {
+			defer func() {
+				if r, _ := recover().(error); r == nil || r.Error() != tst.err {
+					t.Errorf("%s: New panicked with %v, want %q", tst.name, r, tst.err)
+				}
+			}()
+			dstarlite.New(tst.data, tst.start, tst.goal)
+		}()
+	}
+}
+
+func TestStartIsGoal(t *testing.T) {
+	g := maze(9, 1)
+	c := grid.Coord{X: 4, Y: 4}
+	tests := []struct {
+		name       string
+		p          func() *dstarlite.Planner
+		expansions bool // Whether planning may expand vertices.
+	}{
+		{"New", func() *dstarlite.Planner {
+			return dstarlite.New(g, c, c)
+		}, false},
+		{"NewMulti", func() *dstarlite.Planner {
+			return dstarlite.NewMulti(g, c, []dstarlite.State{grid.Coord{X: 0, Y: 0}, c})
+		}, false},
+		{"blocked cell", func() *dstarlite.Planner {
+			return dstarlite.New(g, grid.Coord{X: 1, Y: 1}, grid.Coord{X: 1, Y: 1})
+		}, false},
+		{"moved onto goal", func() *dstarlite.Planner {
+			p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, c)
+			p.Plan()
+			p.UpdateStart(c)
+			return p
+		}, true},
+	}
+	for _, tst := range tests {
+		p := tst.p()
+		path, err := p.PlanE()
+		if err != nil {
+			t.Fatalf("%s: %v", tst.name, err)
+		}
+		if want := []dstarlite.State{p.Start()}; !reflect.DeepEqual(path, want) {
+			t.Errorf("%s: got path %v, want %v", tst.name, path, want)
+		}
+		if p.PathCost() != 0 {
+			t.Errorf("%s: PathCost() = %v, want 0", tst.name, p.PathCost())
+		}
+		if n := p.LastExpansions(); n != 0 && !tst.expansions {
+			t.Errorf("%s: expanded %d vertices, want 0", tst.name, n)
+		}
+	}
+
+	q := dstarlite.NewOf[cell](openGrid{4}, cell{2, 2}, cell{2, 2})
+	if path := q.Plan(); !reflect.DeepEqual(path, []cell{{2, 2}}) || q.PathCost() != 0 {
+		t.Errorf("PlannerOf: got path %v of cost %v, want [{2 2}] of cost 0", path, q.PathCost())
+	}
+}
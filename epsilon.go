@@ -0,0 +1,35 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// SetEpsilon sets the factor e >= 1 by which the planner inflates the Dist
+// heuristic, trading optimality for speed: with e > 1 the planner is greedier
+// and expands far fewer vertices on large maps, while the cost of the path it
+// returns is guaranteed to be at most e times the cost of the optimal path.
+// The default of one plans optimal paths. Values below one are treated as
+// one.
+//
+// The factor should be set before the first call to Plan; changing it later
+// requires re-keying the whole priority queue, and makes the next call to Plan
+// resume the search, since a lower factor may require expanding more
+// vertices. Like Plan, SetEpsilon takes the lock of the planner.
+func (s *PlannerOf[S]) SetEpsilon(e float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.epsilon = e
+	for _, item := range s.QueueSnapshot() {
+		s.u.Update(item.State, s.calcKey(item.State))
+	}
+	s.dirty = true
+	s.walked = false
+}
+
+// inflation returns the heuristic inflation factor, see SetEpsilon.
+func (s *PlannerOf[S]) inflation() float64 {
+	if s.epsilon < 1 {
+		return 1
+	}
+	return s.epsilon
+}
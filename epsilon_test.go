@@ -0,0 +1,97 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// openMaze returns a maze of size by size cells (size must be odd) with every
+// third row and column opened up, such that many paths of nearly equal cost
+// exist.
+func openMaze(size int, seed int64) *grid.Grid {
+	g := maze(size, seed)
+	for i := 1; i < size; i += 3 {
+		for j := 0; j < size; j++ {
+			g.SetBlocked(grid.Coord{X: j, Y: i}, false)
+			g.SetBlocked(grid.Coord{X: i, Y: j}, false)
+		}
+	}
+	return g
+}
+
+func TestEpsilonBound(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		g := openMaze(41, seed)
+		start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 40, Y: 40}
+		opt := dstarlite.New(g, start, goal)
+		opt.Plan()
+		optimal := opt.PathCost()
+		for _, e := range []float64{0.5, 1, 1.5, 2, 5} {
+			p := dstarlite.New(g, start, goal)
+			p.SetEpsilon(e)
+			if p.Plan() == nil {
+				t.Fatalf("seed %d, epsilon %v: no path found", seed, e)
+			}
+			bound := optimal * e
+			if e < 1 {
+				bound = optimal
+			}
+			if p.PathCost() > bound && !costsEqual(p.PathCost(), bound) {
+				t.Errorf("seed %d, epsilon %v: path cost %v exceeds %v", seed, e, p.PathCost(), bound)
+			}
+			if e > 1 && p.LastExpansions() > opt.LastExpansions() {
+				t.Errorf("seed %d, epsilon %v: expanded %d vertices, more than the %d of an optimal planner", seed, e, p.LastExpansions(), opt.LastExpansions())
+			}
+		}
+	}
+}
+
+func TestEpsilonReplan(t *testing.T) {
+	g := openMaze(41, 1)
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 40, Y: 40}
+	p := dstarlite.New(g, start, goal)
+	p.SetEpsilon(3)
+	p.Plan()
+
+	// Lowering the factor after planning must resume the search, such that
+	// the path becomes optimal.
+	p.SetEpsilon(1)
+	if !p.Dirty() {
+		t.Error("planner is not dirty after SetEpsilon")
+	}
+	p.Plan()
+	opt := dstarlite.New(g, start, goal)
+	opt.Plan()
+	if !costsEqual(p.PathCost(), opt.PathCost()) {
+		t.Errorf("path cost after SetEpsilon(1) is %v, want %v", p.PathCost(), opt.PathCost())
+	}
+}
+
+// The benchmarks below plan across a large open maze optimally and with a
+// heuristic inflated by two, reporting the vertices expanded per plan.
+
+func benchmarkEpsilon(b *testing.B, e float64) {
+	g := openMaze(201, 1)
+	var expansions int
+	for i := 0; i < b.N; i++ {
+		p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 200, Y: 200})
+		p.SetEpsilon(e)
+		p.Plan()
+		expansions += p.LastExpansions()
+	}
+	b.ReportMetric(float64(expansions)/float64(b.N), "expansions/op")
+}
+
+func BenchmarkEpsilon1(b *testing.B) {
+	benchmarkEpsilon(b, 1)
+}
+
+func BenchmarkEpsilon2(b *testing.B) {
+	benchmarkEpsilon(b, 2)
+}
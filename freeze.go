@@ -0,0 +1,94 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// frozenData is an immutable snapshot of the graph of a Data interface.
+type frozenData struct {
+	d     Data
+	index map[State]int
+	succ  [][]State
+	pred  [][]State
+	cost  []map[State]float64 // cost[i][n] is the cost from state i to n.
+}
+
+// FreezeData returns an immutable snapshot of the successors, predecessors
+// and edge costs of the given states, stored in flat arrays such that
+// planning through it repeatedly performs no allocations and does not call
+// back into d (except for Dist, which is computed on demand since it is
+// defined for every pair of states).
+//
+// States not in the list have no successors or predecessors, and edges to
+// them are dropped. The slices returned by Succ and Pred are shared, so they
+// must not be modified (appending to them is safe, as they have no spare
+// capacity); the planner copies them before reordering them (see
+// SetSuccessorOrder).
+//
+// The snapshot is only valid for as long as the map described by d is static:
+// later changes to d are not reflected by it, so it must be frozen again (and
+// a new planner created) after any change.
+func FreezeData(d Data, states []State) Data {
+	f := &frozenData{
+		d:     d,
+		index: make(map[State]int, len(states)),
+		succ:  make([][]State, len(states)),
+		pred:  make([][]State, len(states)),
+		cost:  make([]map[State]float64, len(states)),
+	}
+	for i, s := range states {
+		f.index[s] = i
+	}
+	for i, s := range states {
+		f.cost[i] = make(map[State]float64)
+		for _, n := range d.Succ(s) {
+			if _, ok := f.index[n]; ok {
+				f.succ[i] = append(f.succ[i], n)
+				f.cost[i][n] = d.Cost(s, n)
+			}
+		}
+		for _, n := range d.Pred(s) {
+			if _, ok := f.index[n]; ok {
+				f.pred[i] = append(f.pred[i], n)
+			}
+		}
+	}
+	return f
+}
+
+func (f *frozenData) Succ(s State) []State {
+	i, ok := f.index[s]
+	if !ok {
+		return nil
+	}
+	succ := f.succ[i]
+	return succ[:len(succ):len(succ)]
+}
+
+func (f *frozenData) Pred(s State) []State {
+	i, ok := f.index[s]
+	if !ok {
+		return nil
+	}
+	pred := f.pred[i]
+	return pred[:len(pred):len(pred)]
+}
+
+func (f *frozenData) Dist(a, b State) float64 {
+	return f.d.Dist(a, b)
+}
+
+func (f *frozenData) Cost(a, b State) float64 {
+	i, ok := f.index[a]
+	if !ok {
+		return math.Inf(1)
+	}
+	if c, ok := f.cost[i][b]; ok {
+		return c
+	}
+	return math.Inf(1)
+}
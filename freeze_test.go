@@ -0,0 +1,80 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// gridCells returns every cell of the grid g.
+func gridCells(g *grid.Grid) []dstarlite.State {
+	w, h := g.Size()
+	cells := make([]dstarlite.State, 0, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cells = append(cells, grid.Coord{X: x, Y: y})
+		}
+	}
+	return cells
+}
+
+func TestFreezeDataPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		g     *grid.Grid
+		order func(s dstarlite.State, succ []dstarlite.State)
+	}{
+		{"maze", maze(31, 1), nil},
+		{"other maze", maze(31, 2), nil},
+		{"open", grid.New(16, 16), nil},
+		{"open, reordered", grid.New(16, 16), successorOrders[1].order},
+	}
+	for _, tst := range tests {
+		w, h := tst.g.Size()
+		start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: w - 1, Y: h - 1}
+		live := dstarlite.New(tst.g, start, goal)
+		live.SetSuccessorOrder(tst.order)
+		want := live.Plan()
+		if want == nil {
+			t.Fatalf("%s: no path found", tst.name)
+		}
+
+		// Plan twice through the same snapshot, such that any change made to
+		// its shared slices by the first planner shows in the second path.
+		d := dstarlite.FreezeData(tst.g, gridCells(tst.g))
+		for i := 0; i < 2; i++ {
+			frozen := dstarlite.New(d, start, goal)
+			frozen.SetSuccessorOrder(tst.order)
+			if path := frozen.Plan(); !reflect.DeepEqual(path, want) {
+				t.Errorf("%s: frozen path %v, want %v", tst.name, path, want)
+			}
+		}
+	}
+}
+
+// The benchmarks below plan repeatedly through the same maze, through the
+// grid itself and through a frozen snapshot of it.
+
+func BenchmarkPlanLive(b *testing.B) {
+	g := maze(63, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 62, Y: 62}).Plan()
+	}
+}
+
+func BenchmarkPlanFrozen(b *testing.B) {
+	g := maze(63, 1)
+	d := dstarlite.FreezeData(g, gridCells(g))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dstarlite.New(d, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 62, Y: 62}).Plan()
+	}
+}
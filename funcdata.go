@@ -0,0 +1,54 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// FuncData implements the Data interface by calling its function fields, such
+// that quick experiments need not define a type:
+//
+//  p := dstarlite.New(dstarlite.FuncData{
+//      SuccFn: neighbors,
+//      PredFn: neighbors,
+//      DistFn: manhattan,
+//      CostFn: cost,
+//  }, start, goal)
+//
+// All four functions must be set; calling a method whose function is nil
+// panics.
+type FuncData struct {
+	SuccFn, PredFn func(s State) []State
+	DistFn, CostFn func(a, b State) float64
+}
+
+// Succ implements the Data interface.
+func (f FuncData) Succ(s State) []State {
+	if f.SuccFn == nil {
+		panic("dstarlite: FuncData.SuccFn is nil")
+	}
+	return f.SuccFn(s)
+}
+
+// Pred implements the Data interface.
+func (f FuncData) Pred(s State) []State {
+	if f.PredFn == nil {
+		panic("dstarlite: FuncData.PredFn is nil")
+	}
+	return f.PredFn(s)
+}
+
+// Dist implements the Data interface.
+func (f FuncData) Dist(a, b State) float64 {
+	if f.DistFn == nil {
+		panic("dstarlite: FuncData.DistFn is nil")
+	}
+	return f.DistFn(a, b)
+}
+
+// Cost implements the Data interface.
+func (f FuncData) Cost(a, b State) float64 {
+	if f.CostFn == nil {
+		panic("dstarlite: FuncData.CostFn is nil")
+	}
+	return f.CostFn(a, b)
+}
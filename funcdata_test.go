@@ -0,0 +1,37 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestFuncData(t *testing.T) {
+	g := graph{
+		{"a", "b"}: 1, {"b", "d"}: 1,
+		{"a", "c"}: 1, {"c", "d"}: 3,
+	}
+	d := dstarlite.FuncData{
+		SuccFn: g.Succ,
+		PredFn: g.Pred,
+		DistFn: g.Dist,
+		CostFn: g.Cost,
+	}
+	want := dstarlite.New(g, node("a"), node("d")).Plan()
+	if path := dstarlite.New(d, node("a"), node("d")).Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("got path %v, want %v", path, want)
+	}
+
+	d.DistFn = nil
+	defer func() {
+		if recover() == nil {
+			t.Error("Dist with a nil DistFn did not panic")
+		}
+	}()
+	d.Dist(node("a"), node("d"))
+}
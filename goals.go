@@ -0,0 +1,200 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// isGoal tells if the state is in the goal set.
+func (s *PlannerOf[S]) isGoal(st S) bool {
+	_, ok := s.goalKey(st)
+	return ok
+}
+
+// goalKey returns the state of the goal set that equals st (see eq), and
+// whether there is one.
+func (s *PlannerOf[S]) goalKey(st S) (S, bool) {
+	if _, ok := s.goals[st]; ok || s.equals == nil {
+		return st, ok
+	}
+	for g := range s.goals {
+		if s.equals(st, g) {
+			return g, true
+		}
+	}
+	return st, false
+}
+
+// addGoal adds the state to the goal set, with the given rhs value.
+func (s *PlannerOf[S]) addGoal(g S, rhs float64) {
+	s.goals[g] = rhs
+	s.rhs[g] = rhs
+	s.trace("rhs", g)
+	s.updateVertex(g)
+	s.dirty = true
+}
+
+// AddGoal adds a state to the set of goals of the planner, after which Plan
+// returns the path to whichever goal can be reached at the lowest cost.
+//
+// Adding a goal is equivalent to adding a zero cost edge from it to a virtual
+// goal connected to all others, so it is processed incrementally like an edge
+// cost change passed to FlagChanged: the next call to Plan only expands the
+// states whose cost-to-goal is lowered by the new goal, and returns a path
+// that is optimal with respect to the whole (new) goal set.
+func (s *PlannerOf[S]) AddGoal(g S) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isGoal(g) {
+		return
+	}
+	s.addGoal(g, 0)
+}
+
+// RemoveGoal removes a state from the set of goals of the planner; this is
+// processed incrementally like an edge cost increase passed to FlagChanged,
+// with the same convergence guarantee as AddGoal.
+//
+// If the removed goal is the one returned by Goal, another goal (chosen
+// arbitrarily) takes its place. Removing the last goal leaves the planner
+// without any path.
+func (s *PlannerOf[S]) RemoveGoal(g S) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeGoal(g)
+}
+
+// removeGoal implements RemoveGoal.
+func (s *PlannerOf[S]) removeGoal(g S) {
+	g, ok := s.goalKey(g)
+	if !ok {
+		return
+	}
+	delete(s.goals, g)
+	delete(s.blend, g)
+	if s.eq(s.goal, g) {
+		var none S
+		s.goal = none
+		for other := range s.goals {
+			s.goal = other
+			break
+		}
+	}
+
+	minRhs := math.Inf(1)
+	for _, sPrime := range s.succ(g) {
+		rhsPrime := s.combine(s.d.Cost(g, sPrime), s.g.get(sPrime))
+		if rhsPrime < minRhs {
+			minRhs = rhsPrime
+		}
+	}
+	s.rhs[g] = minRhs
+	s.trace("rhs", g)
+	s.updateVertex(g)
+	s.dirty = true
+}
+
+// UpdateGoal moves the goal returned by Goal to the state g, e.g. to chase a
+// moving target. Other goals added through AddGoal are kept.
+//
+// Although D* Lite normally requires planning from scratch after the goal
+// moves, this planner keys its states by their distance to the start (not the
+// goal), so the move is processed incrementally instead: it is equivalent to
+// AddGoal(g) followed by RemoveGoal of the old goal. The next call to Plan
+// only expands the states whose cost-to-goal changed, which for a goal that
+// moves a short distance is usually far less than a full replan (though in the
+// worst case it may expand as many states).
+func (s *PlannerOf[S]) UpdateGoal(g S) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.eq(g, s.goal) {
+		return
+	}
+	old := s.goal
+	if !s.isGoal(g) {
+		s.addGoal(g, 0)
+	}
+	s.removeGoal(old)
+	s.goal = g
+}
+
+// NewMulti returns a new D* Lite Planner with a set of goals, for which Plan
+// returns the path to whichever goal can be reached at the lowest cost (e.g.
+// the nearest of several exits). The first goal is the one returned by Goal.
+// The goals slice must not be empty.
+func NewMulti(data Data, start State, goals []State) *Planner {
+	if len(goals) == 0 {
+		panic("dstarlite: NewMulti requires at least one goal")
+	}
+	dsl := New(data, start, goals[0])
+	for _, g := range goals[1:] {
+		if !dsl.isGoal(g) {
+			dsl.addGoal(g, 0)
+		}
+	}
+	return dsl
+}
+
+// blendDist returns the weighted mean distance from st to the attractors of
+// a blended planner, see NewBlended.
+func (s *PlannerOf[S]) blendDist(st S) float64 {
+	var dist, total float64
+	for a, w := range s.blend {
+		dist += w * s.d.Dist(st, a)
+		total += w
+	}
+	return dist / total
+}
+
+// NewBlended returns a new D* Lite Planner whose goal set holds several
+// attractors, pulling the path with the given (positive) weights. The goals
+// and weights slices must have the same, non-zero, length.
+//
+// Each weight acts as a head start in cost: an attractor pulls as if it could
+// be reached at a cost lower by its weight, so heavier attractors pull from
+// further away. Plan follows the lowest cost path to the goal set, and
+// wherever several successors are equally cheap it prefers the one with the
+// lowest weighted mean Dist to all attractors, such that where the pull of the
+// attractors balances the path heads towards their weighted centroid (e.g.
+// between two equal ones) rather than straight to one of them.
+//
+// The result is a heuristic field: the path is not the provably optimal path
+// to any single attractor, nor to their centroid.
+func NewBlended(data Data, start State, goals []State, weights []float64) *Planner {
+	if len(goals) == 0 || len(goals) != len(weights) {
+		panic("dstarlite: NewBlended requires one weight per goal")
+	}
+	maxWeight := 0.0
+	for _, w := range weights {
+		if !(w > 0) || math.IsInf(w, 1) {
+			panic("dstarlite: NewBlended weights must be positive and finite")
+		}
+		maxWeight = math.Max(maxWeight, w)
+	}
+	dsl := New(data, start, goals[0])
+	dsl.blend = make(valueMap[State], len(goals))
+	for i, g := range goals {
+		dsl.blend[g] = weights[i]
+		dsl.addGoal(g, maxWeight-weights[i])
+	}
+	return dsl
+}
+
+// PlanTo returns the path from the start to the given goal, as PlanE would
+// if it was the only goal of the planner, e.g. for a detour to a pickup. The
+// planner itself is left untouched: the query is answered by a copy of it (see
+// Clone) whose goal set is replaced, which reuses the previous planning work
+// as far as possible.
+func (s *PlannerOf[S]) PlanTo(goal S) ([]S, error) {
+	c := s.Clone()
+	c.UpdateGoal(goal)
+	for g := range c.goals {
+		if !c.eq(g, goal) {
+			c.removeGoal(g)
+		}
+	}
+	return c.PlanE()
+}
@@ -0,0 +1,179 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestAddRemoveGoal(t *testing.T) {
+	g := maze(21, 4)
+	start, far := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	p := dstarlite.New(g, start, far)
+	want := p.Plan()
+	if want == nil {
+		t.Fatal("no path found")
+	}
+
+	// A goal spawns halfway along the path.
+	near := want[len(want)/2].(grid.Coord)
+	fresh := dstarlite.New(g, start, near)
+	wantNear := fresh.Plan()
+	p.AddGoal(near)
+	if path := p.Plan(); !reflect.DeepEqual(path, wantNear) {
+		t.Errorf("got path %v after adding a closer goal, want %v", path, wantNear)
+	}
+	if n, max := p.LastExpansions(), fresh.LastExpansions(); n > max {
+		t.Errorf("adding a goal expanded %d vertices, planning from scratch %d", n, max)
+	}
+
+	p.RemoveGoal(near)
+	if path := p.Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("got path %v after removing the closer goal, want %v", path, want)
+	}
+	if p.Goal() != far {
+		t.Errorf("Goal() = %v, want %v", p.Goal(), far)
+	}
+	p.RemoveGoal(far)
+	if path := p.Plan(); path != nil {
+		t.Errorf("got path %v without goals, want nil", path)
+	}
+}
+
+func TestNewBlended(t *testing.T) {
+	left, right := grid.Coord{X: 0, Y: 10}, grid.Coord{X: 10, Y: 10}
+	tests := []struct {
+		name    string
+		start   grid.Coord
+		weights []float64
+		between bool       // Whether the path heads straight between the attractors.
+		end     grid.Coord // Ignored if between is set.
+	}{
+		{"equal, centered", grid.Coord{X: 5, Y: 0}, []float64{1, 1}, true, grid.Coord{}},
+		{"equal, off-center", grid.Coord{X: 3, Y: 0}, []float64{1, 1}, false, left},
+		{"lighter nearer", grid.Coord{X: 3, Y: 0}, []float64{1, 6}, false, right},
+		{"heavier nearer", grid.Coord{X: 7, Y: 0}, []float64{1, 6}, false, right},
+	}
+	for _, tst := range tests {
+		g := grid.New(11, 11)
+		p := dstarlite.NewBlended(g, tst.start, []dstarlite.State{left, right}, tst.weights)
+		path := p.Plan()
+		if len(path) == 0 {
+			t.Fatalf("%s: no path", tst.name)
+		}
+		end := path[len(path)-1]
+		if tst.between {
+			// Down the middle column to the row of the attractors, and from
+			// there to either of them.
+			for _, s := range path[:11] {
+				if s.(grid.Coord).X != 5 {
+					t.Errorf("%s: path %v leaves the middle column", tst.name, path)
+					break
+				}
+			}
+			if end != left && end != right {
+				t.Errorf("%s: path ends at %v, want an attractor", tst.name, end)
+			}
+			continue
+		}
+		if end != tst.end {
+			t.Errorf("%s: path ends at %v, want %v", tst.name, end, tst.end)
+		}
+	}
+}
+
+func TestPlanTo(t *testing.T) {
+	g := maze(21, 6)
+	start, far := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	p := dstarlite.New(g, start, far)
+	p.AddGoal(grid.Coord{X: 20, Y: 0})
+	want := p.Plan()
+
+	for _, pickup := range []grid.Coord{{X: 0, Y: 20}, {X: 10, Y: 10}, far} {
+		wantTo := dstarlite.New(g, start, pickup).Plan()
+		path, err := p.PlanTo(pickup)
+		if err != nil || !reflect.DeepEqual(path, wantTo) {
+			t.Errorf("PlanTo(%v) = %v, %v, want %v", pickup, path, err, wantTo)
+		}
+	}
+
+	// The planner itself is untouched.
+	if path := p.Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("after PlanTo got path %v, want %v", path, want)
+	}
+	if p.Goal() != far {
+		t.Errorf("after PlanTo Goal() = %v, want %v", p.Goal(), far)
+	}
+}
+
+func TestPlanToEqualGoal(t *testing.T) {
+	pg := newPlaceGraph(graph{
+		{"a", "b"}: 1,
+		{"b", "c"}: 1,
+		{"a", "d"}: 1,
+	})
+	p := dstarlite.New(pg, pg.places["a"], pg.places["c"])
+	p.AddGoal(pg.places["d"])
+
+	// A distinct pointer to the goal c is the goal c, see place.
+	want := []dstarlite.State{pg.places["a"], pg.places["b"], pg.places["c"]}
+	if path, err := p.PlanTo(&place{"c"}); err != nil || !reflect.DeepEqual(path, want) {
+		t.Errorf("PlanTo(c) = %v, %v, want %v", path, err, want)
+	}
+}
+
+func TestNewMultiNearestGoal(t *testing.T) {
+	// The exits are the goal cell G and the cells marked by E below.
+	const m = `
+.........
+.S......G
+.........
+#######.#
+E.......E
+`
+	var (
+		g0     = grid.Coord{X: 8, Y: 1}
+		far    = grid.Coord{X: 0, Y: 4}
+		near   = grid.Coord{X: 8, Y: 4}
+		start  = grid.Coord{X: 1, Y: 1}
+		corner = grid.Coord{X: 0, Y: 0}
+		wall   = grid.Coord{X: 0, Y: 3}
+	)
+	tests := []struct {
+		name  string
+		goals []dstarlite.State
+		want  dstarlite.State
+		cost  float64
+	}{
+		{"single goal", []dstarlite.State{g0}, g0, 7},
+		{"nearest first", []dstarlite.State{g0, far}, g0, 7},
+		{"nearest last", []dstarlite.State{far, g0}, g0, 7},
+		{"through gap", []dstarlite.State{far, near}, near, 10},
+		{"goal in wall", []dstarlite.State{wall, far}, far, 16},
+		{"start is a goal", []dstarlite.State{g0, start}, start, 0},
+		{"duplicate goals", []dstarlite.State{corner, g0, corner}, corner, 2},
+	}
+	for _, tst := range tests {
+		g, _, _ := parseGrid(m)
+		p := dstarlite.NewMulti(g, start, tst.goals)
+		path := p.Plan()
+		if path == nil {
+			t.Fatalf("%s: no path found", tst.name)
+		}
+		if end := path[len(path)-1]; end != tst.want {
+			t.Errorf("%s: path ends at %v, want %v", tst.name, end, tst.want)
+		}
+		if p.PathCost() != tst.cost {
+			t.Errorf("%s: PathCost() = %v, want %v", tst.name, p.PathCost(), tst.cost)
+		}
+		if p.Goal() != tst.goals[0] {
+			t.Errorf("%s: Goal() = %v, want the first goal %v", tst.name, p.Goal(), tst.goals[0])
+		}
+	}
+}
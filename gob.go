@@ -0,0 +1,109 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"sync"
+)
+
+// gobValue is a single entry of a valueMap, as encoded by GobEncode.
+type gobValue[S comparable] struct {
+	State S
+	Value float64
+}
+
+// gobPlanner is the planner state encoded by GobEncode.
+type gobPlanner[S comparable] struct {
+	Start, Goal S
+	Goals       []gobValue[S]
+	RHS, G      []gobValue[S]
+	Queue       []QueueItemOf[S]
+	Km          float64
+	Dirty       bool
+}
+
+func encodeValues[S comparable](v valueMap[S]) []gobValue[S] {
+	values := make([]gobValue[S], 0, len(v))
+	for st, val := range v {
+		values = append(values, gobValue[S]{st, val})
+	}
+	return values
+}
+
+func decodeValues[S comparable](values []gobValue[S]) valueMap[S] {
+	v := make(valueMap[S], len(values))
+	for _, e := range values {
+		v[e.State] = e.Value
+	}
+	return v
+}
+
+// GobEncode implements the gob.GobEncoder interface. It encodes the planning
+// progress of the planner (the start and goals, the g and rhs values, km and
+// the priority queue, in pop order) such that planning can resume
+// incrementally, and deterministically, after decoding, e.g. when a saved
+// game is loaded.
+//
+// Since State is an interface, the concrete state types must be registered
+// with the gob package (see gob.Register) by the caller. The Data interface and
+// options (like SetTrace or SetCostQuantum) are not encoded.
+func (s *PlannerOf[S]) GobEncode() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gobPlanner[S]{
+		Start: s.start,
+		Goal:  s.goal,
+		Goals: encodeValues(s.goals),
+		RHS:   encodeValues(s.rhs),
+		G:     encodeValues(s.g),
+		Queue: s.QueueSnapshot(),
+		Km:    s.km,
+		Dirty: s.dirty,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode implements the gob.GobDecoder interface. It replaces the planning
+// progress of the planner with the decoded one, keeping its Data interface and
+// options. The planner should hence be created (via New, with the Data the
+// encoded planner used) before decoding into it:
+//
+//  p := dstarlite.New(data, start, goal)
+//  err := gob.NewDecoder(r).Decode(p)
+//
+func (s *PlannerOf[S]) GobDecode(b []byte) error {
+	var dec gobPlanner[S]
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&dec); err != nil {
+		return err
+	}
+	q := s.newQueue()
+	for _, item := range dec.Queue {
+		if q.Contains(item.State) {
+			return errors.New("dstarlite: duplicate state in decoded queue")
+		}
+		q.Insert(item.State, item.Key)
+	}
+
+	if s.mu == nil {
+		s.mu = new(sync.RWMutex)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start = dec.Start
+	s.goal = dec.Goal
+	s.goals = decodeValues(dec.Goals)
+	s.rhs = decodeValues(dec.RHS)
+	s.g = decodeValues(dec.G)
+	s.u = q
+	s.km = dec.Km
+	s.dirty = dec.Dirty
+	s.walked = false
+	s.changed = nil
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	gob.Register(grid.Coord{})
+	tests := []struct {
+		name    string
+		prepare func(g *grid.Grid, p *dstarlite.Planner)
+	}{
+		{"new", func(g *grid.Grid, p *dstarlite.Planner) {}},
+		{"planned", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.Plan()
+		}},
+		{"changed after plan", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.Plan()
+			g.SetBlocked(grid.Coord{X: 3, Y: 3}, false)
+		}},
+		{"moved after plan", func(g *grid.Grid, p *dstarlite.Planner) {
+			p.Plan()
+			p.UpdateStart(grid.Coord{X: 0, Y: 2})
+		}},
+	}
+	for _, tst := range tests {
+		g := maze(15, 2)
+		p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 14, Y: 14})
+		g.SetPlanner(p)
+		tst.prepare(g, p)
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+			t.Fatalf("%s: encode: %v", tst.name, err)
+		}
+		// Decode into a planner with a different start and goal, which the
+		// decoded ones must replace.
+		d := dstarlite.New(g, grid.Coord{X: 14, Y: 0}, grid.Coord{X: 0, Y: 14})
+		if err := gob.NewDecoder(&buf).Decode(d); err != nil {
+			t.Fatalf("%s: decode: %v", tst.name, err)
+		}
+		if d.Dirty() != p.Dirty() {
+			t.Errorf("%s: decoded Dirty() = %v, want %v", tst.name, d.Dirty(), p.Dirty())
+		}
+
+		// Check both the next plan, and a replan after a further change
+		// (flagged to both planners, instead of through the grid).
+		g.SetPlanner(nil)
+		for i := 0; i < 2; i++ {
+			want := p.Plan()
+			if got := d.Plan(); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: decoded planner planned %v, want %v", tst.name, got, want)
+			}
+			if d.LastExpansions() != p.LastExpansions() {
+				t.Errorf("%s: decoded planner expanded %d vertices, want %d", tst.name, d.LastExpansions(), p.LastExpansions())
+			}
+			setBlocked(g, grid.Coord{X: 1, Y: 1}, false, p, d)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// Clearance returns the clearance of every cell of the grid (indexed as
+// y*width+x): the number of steps, moving in any of the eight directions, to
+// the nearest blocked cell or the outside of the grid. Blocked cells have a
+// clearance of zero, passable cells at the edge of the grid a clearance of one.
+func Clearance(g *Grid) []int {
+	field := make([]int, g.width*g.height)
+	var frontier []Coord
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			i := y*g.width + x
+			switch {
+			case g.blocked[i]:
+				field[i] = 0
+			case x == 0 || y == 0 || x == g.width-1 || y == g.height-1:
+				field[i] = 1
+				frontier = append(frontier, Coord{x, y})
+			default:
+				field[i] = -1
+			}
+		}
+	}
+	// Blocked cells seed the flood too.
+	for i, b := range g.blocked {
+		if b {
+			frontier = append(frontier, Coord{i % g.width, i / g.width})
+		}
+	}
+	for len(frontier) > 0 {
+		c := frontier[0]
+		frontier = frontier[1:]
+		d := field[c.Y*g.width+c.X]
+		for y := -1; y <= 1; y++ {
+			for x := -1; x <= 1; x++ {
+				n := Coord{c.X + x, c.Y + y}
+				if !g.InBounds(n) {
+					continue
+				}
+				if i := n.Y*g.width + n.X; field[i] == -1 || field[i] > d+1 {
+					field[i] = d + 1
+					frontier = append(frontier, n)
+				}
+			}
+		}
+	}
+	return field
+}
+
+type maxClearance struct {
+	*Grid
+	field  []int
+	widest int
+}
+
+// MaxClearanceData returns a view of the grid g for planning the path that
+// stays as far from obstacles as possible, with a bottleneck planner:
+//
+//  p := dstarlite.NewBottleneck(grid.MaxClearanceData(g), start, goal)
+//
+// The cost of entering a cell is the largest clearance of the grid minus the
+// clearance of the cell, such that minimizing the maximum cost along the path
+// maximizes the minimum clearance along it (a maximin objective). The path may
+// thus be much longer than the shortest one, e.g. to pass through the wider of
+// two gaps. The clearances are computed once, so create a new view after
+// modifying the grid.
+func MaxClearanceData(g *Grid) dstarlite.Data {
+	field := Clearance(g)
+	widest := 0
+	for _, c := range field {
+		if c > widest {
+			widest = c
+		}
+	}
+	return &maxClearance{g, field, widest}
+}
+
+// Cost implements the dstarlite.Data interface.
+func (m *maxClearance) Cost(a, b dstarlite.State) float64 {
+	if c := m.Grid.Cost(a, b); math.IsInf(c, 1) {
+		return c
+	}
+	bc := b.(Coord)
+	return float64(m.widest - m.field[bc.Y*m.width+bc.X])
+}
@@ -0,0 +1,78 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestClearance(t *testing.T) {
+	g, _, _ := parseGrid(`
+.......
+.......
+...#...
+.......
+`)
+	want := []int{
+		1, 1, 1, 1, 1, 1, 1,
+		1, 2, 1, 1, 1, 2, 1,
+		1, 2, 1, 0, 1, 2, 1,
+		1, 1, 1, 1, 1, 1, 1,
+	}
+	if got := grid.Clearance(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got clearance %v, want %v", got, want)
+	}
+}
+
+func TestMaxClearanceWiderGap(t *testing.T) {
+	// The wall across the room has a one cell gap right below the start, and
+	// a three cell gap far to the right.
+	g, start, goal := parseGrid(`
+...............
+..S............
+...............
+...............
+...............
+##.######...###
+...............
+...............
+...............
+..G............
+...............
+`)
+	passes := func(path []dstarlite.State) (narrow, wide bool) {
+		for _, s := range path {
+			switch c := s.(grid.Coord); {
+			case c.Y != 5:
+			case c.X == 2:
+				narrow = true
+			case c.X >= 9 && c.X <= 11:
+				wide = true
+			}
+		}
+		return
+	}
+	tests := []struct {
+		name   string
+		p      *dstarlite.Planner
+		narrow bool
+	}{
+		{"shortest", dstarlite.New(g, start, goal), true},
+		{"max clearance", dstarlite.NewBottleneck(grid.MaxClearanceData(g), start, goal), false},
+	}
+	for _, tst := range tests {
+		path := tst.p.Plan()
+		if len(path) == 0 {
+			t.Fatalf("%s: no path", tst.name)
+		}
+		if narrow, wide := passes(path); narrow != tst.narrow || wide == tst.narrow {
+			t.Errorf("%s: path %v passes the narrow gap: %v, the wide gap: %v", tst.name, path, narrow, wide)
+		}
+	}
+}
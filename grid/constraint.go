@@ -0,0 +1,91 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+type softConstraint struct {
+	cost   func(x, y int) float64
+	weight float64
+}
+
+// ConstraintSet layers hard and soft constraints on the cells of a grid into
+// a single cost function.
+//
+// Hard constraints always win: a cell that violates any of them is
+// impassable. The costs of the soft constraints are weighted and summed, and
+// the sum may be limited to an overall cap. The zero value is an empty set,
+// which adds no cost to any cell.
+type ConstraintSet struct {
+	hard []func(x, y int) bool
+	soft []softConstraint
+	cap  float64
+}
+
+// AddHard adds a hard constraint, blocked should return true for each cell
+// that may never be entered.
+func (c *ConstraintSet) AddHard(blocked func(x, y int) bool) {
+	c.hard = append(c.hard, blocked)
+}
+
+// AddSoft adds a soft constraint. The cost of entering a cell is increased by
+// weight times the value returned by cost for it. Both the weight and the
+// returned costs must be non-negative, negative values are treated as zero.
+func (c *ConstraintSet) AddSoft(cost func(x, y int) float64, weight float64) {
+	c.soft = append(c.soft, softConstraint{cost, weight})
+}
+
+// SetCap limits the summed soft cost of any single cell to limit. A cap of
+// zero (the default) means the sum is unlimited.
+func (c *ConstraintSet) SetCap(limit float64) {
+	c.cap = limit
+}
+
+// Compile returns the cost function described by the set: it returns +Inf for
+// cells violating a hard constraint, and the (capped) weighted sum of the soft
+// costs otherwise. Constraints added to the set afterwards are not included.
+func (c *ConstraintSet) Compile() func(x, y int) float64 {
+	hard := append([]func(x, y int) bool(nil), c.hard...)
+	soft := append([]softConstraint(nil), c.soft...)
+	limit := c.cap
+	return func(x, y int) float64 {
+		for _, blocked := range hard {
+			if blocked(x, y) {
+				return math.Inf(1)
+			}
+		}
+		var sum float64
+		for _, s := range soft {
+			sum += math.Max(0, s.weight) * math.Max(0, s.cost(x, y))
+		}
+		if limit > 0 && sum > limit {
+			sum = limit
+		}
+		return sum
+	}
+}
+
+// Data returns a view of the grid g in which the cost of entering each cell
+// is increased by the compiled cost of the set. Since the added costs are
+// never negative, the Manhattan distance heuristic of the grid remains
+// admissible.
+func (c *ConstraintSet) Data(g *Grid) dstarlite.Data {
+	return &constrained{g, c.Compile()}
+}
+
+type constrained struct {
+	*Grid
+	extra func(x, y int) float64
+}
+
+// Cost implements the dstarlite.Data interface.
+func (c *constrained) Cost(a, b dstarlite.State) float64 {
+	bc := b.(Coord)
+	return c.Grid.Cost(a, b) + c.extra(bc.X, bc.Y)
+}
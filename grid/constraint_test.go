@@ -0,0 +1,70 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestConstraintSetRingAndGradient(t *testing.T) {
+	// A ring of walls five cells around the center of a 13x13 grid, and a
+	// soft cost that grows with the distance from the top of the ring.
+	ring := func(x, y int) int {
+		dx, dy := x-6, y-6
+		if dx < 0 {
+			dx = -dx
+		}
+		if dy < 0 {
+			dy = -dy
+		}
+		if dx > dy {
+			return dx
+		}
+		return dy
+	}
+	var cs grid.ConstraintSet
+	cs.AddHard(func(x, y int) bool { return ring(x, y) == 5 })
+	cs.AddSoft(func(x, y int) float64 { return float64(y - 2) }, 2)
+	d := cs.Data(grid.New(13, 13))
+
+	tests := []struct {
+		name        string
+		start, goal grid.Coord
+		minY        int // Smallest row the path should pass through, -1 for no path.
+	}{
+		{"across the ring", grid.Coord{X: 2, Y: 6}, grid.Coord{X: 10, Y: 6}, 2},
+		{"along the ring", grid.Coord{X: 2, Y: 3}, grid.Coord{X: 10, Y: 3}, 2},
+		{"out of the ring", grid.Coord{X: 6, Y: 6}, grid.Coord{X: 0, Y: 0}, -1},
+	}
+	for _, tst := range tests {
+		path := dstarlite.New(d, tst.start, tst.goal).Plan()
+		if tst.minY < 0 {
+			if path != nil {
+				t.Errorf("%s: got path %v crossing the ring", tst.name, path)
+			}
+			continue
+		}
+		if path == nil {
+			t.Fatalf("%s: no path found", tst.name)
+		}
+		minY := tst.start.Y
+		for _, s := range path {
+			c := s.(grid.Coord)
+			if ring(c.X, c.Y) >= 5 {
+				t.Errorf("%s: path %v leaves the ring at %v", tst.name, path, c)
+				break
+			}
+			if c.Y < minY {
+				minY = c.Y
+			}
+		}
+		if minY != tst.minY {
+			t.Errorf("%s: path %v reaches row %d, want %d", tst.name, path, minY, tst.minY)
+		}
+	}
+}
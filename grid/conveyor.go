@@ -0,0 +1,126 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// ConveyorData plans through a grid in which some cells are conveyor belts.
+// It implements the dstarlite.Data interface.
+//
+// A conveyor cell may be entered normally, but once inside it the only
+// successor is the neighboring cell in the direction of the conveyor, which
+// is reached at the (usually low or zero) conveyor cost. Chains of conveyor
+// cells thus carry the agent along until it is pushed off the end. A conveyor
+// pushing into a blocked or out-of-bounds cell has no way out.
+//
+// Since the forced moves are always single successors, the gradient walk
+// performed by Plan simply follows the chain and never loops, even at zero
+// cost. A closed loop of conveyors that does not hold the goal has no path to
+// the goal, and as such is never walked into.
+type ConveyorData struct {
+	g     *Grid
+	cost  float64
+	belts map[Coord]Coord
+}
+
+// SetConveyor makes the specified cell a conveyor pushing in the direction
+// dir, which must be one of the unit vectors {1, 0}, {0, 1}, {-1, 0} or
+// {0, -1}. A zero direction removes the conveyor.
+func (d *ConveyorData) SetConveyor(c Coord, dir Coord) {
+	if dir == (Coord{}) {
+		delete(d.belts, c)
+		return
+	}
+	if abs(dir.X)+abs(dir.Y) != 1 {
+		panic("grid: conveyor direction must be a unit vector")
+	}
+	d.belts[c] = dir
+}
+
+// Conveyor returns the direction of the conveyor in the specified cell, and
+// whether or not the cell is a conveyor at all.
+func (d *ConveyorData) Conveyor(c Coord) (dir Coord, ok bool) {
+	dir, ok = d.belts[c]
+	return
+}
+
+// dest returns the cell that the conveyor at c pushes onto.
+func (d *ConveyorData) dest(c, dir Coord) Coord {
+	return Coord{c.X + dir.X, c.Y + dir.Y}
+}
+
+// Succ implements the dstarlite.Data interface.
+func (d *ConveyorData) Succ(s dstarlite.State) []dstarlite.State {
+	c := s.(Coord)
+	if dir, ok := d.belts[c]; ok {
+		dst := d.dest(c, dir)
+		if !d.g.InBounds(dst) {
+			return nil
+		}
+		return []dstarlite.State{dst}
+	}
+	return d.g.neighbors(c)
+}
+
+// Pred implements the dstarlite.Data interface.
+func (d *ConveyorData) Pred(s dstarlite.State) []dstarlite.State {
+	c := s.(Coord)
+	all := d.g.neighbors(c)
+	pred := all[:0]
+	for _, n := range all {
+		nc := n.(Coord)
+		if dir, ok := d.belts[nc]; ok && d.dest(nc, dir) != c {
+			continue
+		}
+		pred = append(pred, n)
+	}
+	return pred
+}
+
+// Dist implements the dstarlite.Data interface. It is the Manhattan distance
+// scaled down by the conveyor cost (when it is less than one) such that it
+// never overestimates a path riding the conveyors. With a zero conveyor cost
+// it is always zero, and the planner degrades to Dijkstra's algorithm.
+func (d *ConveyorData) Dist(a, b dstarlite.State) float64 {
+	return d.g.Dist(a, b) * math.Min(1, d.cost)
+}
+
+// Cost implements the dstarlite.Data interface.
+func (d *ConveyorData) Cost(a, b dstarlite.State) float64 {
+	ac := a.(Coord)
+	bc := b.(Coord)
+	if dir, ok := d.belts[ac]; ok {
+		if d.dest(ac, dir) != bc || d.g.Blocked(bc) {
+			return math.Inf(1)
+		}
+		return d.cost
+	}
+	return d.g.Cost(a, b)
+}
+
+// NewConveyor returns a new view of the grid g in which conveyor cells push
+// the agent onwards at the given (non-negative) cost per forced move.
+// Initially no cell is a conveyor.
+func NewConveyor(g *Grid, cost float64) *ConveyorData {
+	if cost < 0 {
+		panic("grid: conveyor cost must be non-negative")
+	}
+	return &ConveyorData{
+		g:     g,
+		cost:  cost,
+		belts: make(map[Coord]Coord),
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestConveyorShortcut(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  grid.Coord // Direction of the conveyors along the top row.
+		end  bool       // Whether to block the cell the conveyors push onto.
+		cost float64
+	}{
+		{"no conveyors", grid.Coord{}, false, 10},
+		{"towards the goal", grid.Coord{X: 1}, false, 3},
+		{"away from the goal", grid.Coord{X: -1}, false, 10},
+		{"into a wall", grid.Coord{X: 1}, true, 10},
+	}
+	for _, tst := range tests {
+		// The top row is a shortcut only when riding the conveyors, walking
+		// it costs as much as walking the bottom row.
+		g, start, goal := parseGrid(`
+.........
+S#######G
+.........
+`)
+		d := grid.NewConveyor(g, 0)
+		for x := 1; x < 8; x++ {
+			d.SetConveyor(grid.Coord{X: x, Y: 0}, tst.dir)
+		}
+		if tst.end {
+			g.SetBlocked(grid.Coord{X: 8, Y: 0}, true)
+		}
+		path := dstarlite.New(d, start, goal).Plan()
+		if path == nil {
+			t.Fatalf("%s: no path found", tst.name)
+		}
+		cost := 0.0
+		for i := 1; i < len(path); i++ {
+			from, to := path[i-1].(grid.Coord), path[i].(grid.Coord)
+			if dir, ok := d.Conveyor(from); ok && to != (grid.Coord{X: from.X + dir.X, Y: from.Y + dir.Y}) {
+				t.Errorf("%s: path moves from conveyor %v to %v against direction %v", tst.name, from, to, dir)
+			}
+			cost += d.Cost(from, to)
+		}
+		if cost != tst.cost {
+			t.Errorf("%s: path %v costs %v, want %v", tst.name, path, cost, tst.cost)
+		}
+	}
+}
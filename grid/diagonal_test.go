@@ -0,0 +1,66 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestDiagonal(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      string
+		states int
+		cost   float64
+	}{
+		{"open", `
+S....
+.....
+.....
+.....
+....G
+`, 5, 4 * math.Sqrt2},
+		{"no corner cutting", `
+S#
+.G
+`, 3, 2},
+		{"around a wall", `
+S...
+##..
+...G
+`, 5, 3 + math.Sqrt2},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m, grid.Diagonal())
+		path := dstarlite.New(g, start, goal).Plan()
+		if len(path) != tst.states {
+			t.Errorf("%s: got path %v, want %d states", tst.name, path, tst.states)
+			continue
+		}
+		if cost := pathCost(g, path); math.Abs(cost-tst.cost) > 1e-9 {
+			t.Errorf("%s: path %v costs %v, want %v", tst.name, path, cost, tst.cost)
+		}
+	}
+}
+
+func TestDiagonalSaveLoad(t *testing.T) {
+	g := grid.New(4, 3, grid.Diagonal())
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := grid.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Diagonal() {
+		t.Error("loaded grid is not 8-connected")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// crosses tells if the path moves from cell a to cell b.
+func crosses(path []dstarlite.State, a, b grid.Coord) bool {
+	for i := 1; i < len(path); i++ {
+		if path[i-1] == a && path[i] == b {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDoorAsymmetric(t *testing.T) {
+	// The wall has a gap below the door, and another far to the right.
+	g, _, _ := parseGrid(`
+...........
+...........
+##.#####.##
+...........
+`)
+	outside, inside := grid.Coord{X: 2, Y: 1}, grid.Coord{X: 2, Y: 2}
+	g.AddDoor(outside, inside, 1, 100)
+	top, bottom := grid.Coord{X: 2, Y: 0}, grid.Coord{X: 2, Y: 3}
+
+	in := dstarlite.New(g, top, bottom)
+	if path := in.Plan(); len(path) != 4 || !crosses(path, outside, inside) {
+		t.Errorf("inbound path %v, want one through the door", path)
+	}
+	out := dstarlite.New(g, bottom, top)
+	if path := out.Plan(); len(path) != 16 || crosses(path, inside, outside) {
+		t.Errorf("outbound path %v, want the detour around the door", path)
+	}
+
+	// Locking the door makes the inbound path detour as well, and unlocking
+	// it restores the path through it.
+	g.SetPlanner(in)
+	g.AddDoor(outside, inside, math.Inf(1), 100)
+	if path := in.Plan(); len(path) != 16 || crosses(path, outside, inside) {
+		t.Errorf("inbound path %v through the locked door, want the detour", path)
+	}
+	g.RemoveDoor(outside, inside)
+	if path := in.Plan(); len(path) != 4 || !crosses(path, outside, inside) {
+		t.Errorf("inbound path %v after removing the door, want one through it", path)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+type footprint struct {
+	*Grid
+	w, h int
+}
+
+// WithFootprint returns a view of the grid g for planning a large unit that
+// occupies w by h cells.
+//
+// States are the anchor cell of the unit, which is its top-left (smallest X
+// and Y) cell; the unit then covers the cells from the anchor up to, but not
+// including, anchor.X+w and anchor.Y+h. An anchor is passable only if every
+// cell the unit covers there is in bounds and passable, so the unit never
+// squeezes through gaps narrower than itself. Paths returned by a planner are
+// sequences of anchors.
+func WithFootprint(g *Grid, w, h int) dstarlite.Data {
+	if w < 1 || h < 1 {
+		panic("grid: footprint must be at least one cell")
+	}
+	return &footprint{g, w, h}
+}
+
+// fits tells if the unit fits inside the grid when anchored at c.
+func (f *footprint) fits(c Coord) bool {
+	return c.X >= 0 && c.Y >= 0 && c.X+f.w <= f.width && c.Y+f.h <= f.height
+}
+
+func (f *footprint) neighbors(c Coord) []dstarlite.State {
+	all := f.Grid.neighbors(c)
+	n := all[:0]
+	for _, s := range all {
+		if f.fits(s.(Coord)) {
+			n = append(n, s)
+		}
+	}
+	return n
+}
+
+// Succ implements the dstarlite.Data interface.
+func (f *footprint) Succ(s dstarlite.State) []dstarlite.State {
+	return f.neighbors(s.(Coord))
+}
+
+// Pred implements the dstarlite.Data interface.
+func (f *footprint) Pred(s dstarlite.State) []dstarlite.State {
+	return f.neighbors(s.(Coord))
+}
+
+// Cost implements the dstarlite.Data interface. Every cell the unit covers
+// makes the same move as its anchor, and the move costs the most that any of
+// them costs on the grid: it is +Inf if the unit covers a blocked cell at
+// either anchor, or if any cell would cut a blocked corner, and otherwise
+// accounts for the doors and diagonal moves of the cells.
+func (f *footprint) Cost(a, b dstarlite.State) float64 {
+	ac := a.(Coord)
+	bc := b.(Coord)
+	if !f.fits(ac) || !f.fits(bc) {
+		return math.Inf(1)
+	}
+	var cost float64
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			c := f.Grid.Cost(Coord{ac.X + x, ac.Y + y}, Coord{bc.X + x, bc.Y + y})
+			if c > cost {
+				cost = c
+			}
+		}
+	}
+	return cost
+}
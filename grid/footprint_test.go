@@ -0,0 +1,81 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestFootprintWideCorridor(t *testing.T) {
+	// The wall across the third row has a gap one cell wide near the start
+	// and goal, and one two cells wide far off to the right. Anchors are the
+	// top-left cell of the unit, so every unit fits at the start and goal.
+	g, start, goal := parseGrid(`
+S.........
+..........
+####.##..#
+G.........
+..........
+`)
+	tests := []struct {
+		name string
+		w, h int
+		gap  int // Column at which the anchor crosses the wall.
+	}{
+		{"1x1", 1, 1, 4},
+		{"2x2", 2, 2, 7},
+		{"2x1", 2, 1, 7},
+		{"1x2", 1, 2, 4},
+	}
+	for _, tst := range tests {
+		d := grid.WithFootprint(g, tst.w, tst.h)
+		path := dstarlite.New(d, start, goal).Plan()
+		if path == nil {
+			t.Fatalf("%s: no path found", tst.name)
+		}
+		for _, s := range path {
+			c := s.(grid.Coord)
+			for y := c.Y; y < c.Y+tst.h; y++ {
+				for x := c.X; x < c.X+tst.w; x++ {
+					if g.Blocked(grid.Coord{X: x, Y: y}) {
+						t.Fatalf("%s: unit anchored at %v covers blocked cell (%d, %d)", tst.name, c, x, y)
+					}
+				}
+			}
+			if c.Y <= 2 && c.Y+tst.h > 2 && c.X != tst.gap {
+				t.Errorf("%s: path %v crosses the wall at column %d, want %d", tst.name, path, c.X, tst.gap)
+				break
+			}
+		}
+	}
+}
+
+func TestFootprintCost(t *testing.T) {
+	g := grid.New(4, 4, grid.Diagonal())
+	g.AddDoor(grid.Coord{X: 1, Y: 0}, grid.Coord{X: 1, Y: 1}, 5, 1)
+	g.SetBlocked(grid.Coord{X: 3, Y: 2}, true)
+	d := grid.WithFootprint(g, 2, 1)
+	tests := []struct {
+		name string
+		a, b grid.Coord
+		cost float64
+	}{
+		{"plain", grid.Coord{X: 0, Y: 2}, grid.Coord{X: 0, Y: 3}, 1},
+		{"second cell through a door", grid.Coord{X: 0, Y: 0}, grid.Coord{X: 0, Y: 1}, 5},
+		{"door the cheap way", grid.Coord{X: 0, Y: 1}, grid.Coord{X: 0, Y: 0}, 1},
+		{"diagonal", grid.Coord{X: 0, Y: 1}, grid.Coord{X: 1, Y: 2}, math.Sqrt2},
+		{"onto a blocked cell", grid.Coord{X: 2, Y: 1}, grid.Coord{X: 2, Y: 2}, math.Inf(1)},
+		{"cutting a blocked corner", grid.Coord{X: 1, Y: 2}, grid.Coord{X: 2, Y: 3}, math.Inf(1)},
+	}
+	for _, tst := range tests {
+		if cost := d.Cost(tst.a, tst.b); cost != tst.cost {
+			t.Errorf("%s: Cost(%v, %v) = %v, want %v", tst.name, tst.a, tst.b, cost, tst.cost)
+		}
+	}
+}
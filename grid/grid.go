@@ -0,0 +1,374 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package grid implements D* Lite Data for two-dimensional grids.
+//
+// A Grid is a rectangular, 4-connected (or, optionally, 8-connected) map of
+// cells where each cell is either passable or blocked. Since *Grid implements
+// the dstarlite.Data interface it can be handed directly to dstarlite.New:
+//
+//  g := grid.New(32, 32)
+//  g.SetBlocked(grid.Coord{4, 4}, true)
+//  p := dstarlite.New(g, grid.Coord{0, 0}, grid.Coord{31, 31})
+//  path := p.Plan()
+//
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// Coord represents a single cell in a grid. It implements the dstarlite.State
+// interface.
+type Coord struct {
+	X, Y int
+}
+
+// Equals implements the dstarlite.State interface.
+func (c Coord) Equals(other dstarlite.State) bool {
+	o, ok := other.(Coord)
+	return ok && o == c
+}
+
+// directions holds the unit vector of each of the four movement directions.
+var directions = [...]Coord{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+
+// diagonals holds the vector of each of the four diagonal movement directions,
+// such that diagonals[i] lies between directions[i] and directions[i+1].
+var diagonals = [...]Coord{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
+
+// edge is a directed edge between two neighboring cells.
+type edge struct {
+	from, to Coord
+}
+
+// Grid is a rectangular 4-connected (or 8-connected) grid of cells.
+type Grid struct {
+	width, height int
+	blocked       []bool
+	doors         map[edge]float64
+	planner       *dstarlite.Planner
+	diagonal      bool
+	toroidal      bool
+	heuristic     Heuristic
+
+	// Weights of the cells (nil if all are one), and the minimum weight that
+	// Dist is scaled by, see the Weights option.
+	weights   []float64
+	minWeight float64
+}
+
+// Option configures a grid, see New.
+type Option func(g *Grid)
+
+// Diagonal makes the grid 8-connected: besides the four cardinal neighbors,
+// each cell is connected to its four diagonal neighbors at a cost of Sqrt2,
+// and Dist returns the octile distance. Diagonal moves may not cut corners,
+// i.e. they are impassable if either of the two cells they pass by is blocked.
+func Diagonal() Option {
+	return func(g *Grid) {
+		g.diagonal = true
+	}
+}
+
+// Diagonal tells if the grid is 8-connected, see the Diagonal option.
+func (g *Grid) Diagonal() bool {
+	return g.diagonal
+}
+
+// Toroidal makes the grid wrap around at its edges, like the world of many
+// games: moving off one edge enters the cell at the opposite edge, so e.g. the
+// cells Coord{0, y} and Coord{width-1, y} are neighbors. Dist then measures
+// the shorter of the direct and the wrapped distance along each axis, which
+// keeps every heuristic exactly as admissible as on a grid without wrapping.
+//
+// Only the moves made by the grid itself (through Succ and Pred) wrap, Data
+// types that compute moves on their own, like TurnLimitedData or JumpData,
+// and helpers like LineOfSight do not. Doors may only be placed between cells
+// that are neighbors without wrapping.
+func Toroidal() Option {
+	return func(g *Grid) {
+		g.toroidal = true
+	}
+}
+
+// Toroidal tells if the grid wraps around at its edges, see the Toroidal
+// option.
+func (g *Grid) Toroidal() bool {
+	return g.toroidal
+}
+
+// wrap returns the cell at c, wrapped into the grid if it is toroidal.
+func (g *Grid) wrap(c Coord) Coord {
+	if !g.toroidal {
+		return c
+	}
+	return Coord{mod(c.X, g.width), mod(c.Y, g.height)}
+}
+
+// mod returns a modulo b, in the range [0, b).
+func mod(a, b int) int {
+	return ((a % b) + b) % b
+}
+
+// SetPlanner sets the planner that the grid notifies (via FlagChanged) of
+// every edge cost change made through its methods, such that the next call to
+// Plan takes them into account. Nil (the default) disables notification.
+func (g *Grid) SetPlanner(p *dstarlite.Planner) {
+	g.planner = p
+}
+
+// cellEdges returns every edge whose cost depends on the specified cell: those
+// into and out of it and, in 8-connected grids, the diagonal moves passing by
+// it.
+func (g *Grid) cellEdges(c Coord) []edge {
+	var edges []edge
+	for _, n := range g.neighbors(c) {
+		nc := n.(Coord)
+		edges = append(edges, edge{nc, c}, edge{c, nc})
+	}
+	if g.diagonal {
+		for i, a := range directions {
+			b := directions[(i+1)%len(directions)]
+			ac := g.wrap(Coord{c.X + a.X, c.Y + a.Y})
+			bc := g.wrap(Coord{c.X + b.X, c.Y + b.Y})
+			if g.InBounds(ac) && g.InBounds(bc) && ac != bc {
+				edges = append(edges, edge{ac, bc}, edge{bc, ac})
+			}
+		}
+	}
+	return edges
+}
+
+// costs returns the current cost of each edge.
+func (g *Grid) costs(edges []edge) []float64 {
+	c := make([]float64, len(edges))
+	for i, e := range edges {
+		c[i] = g.Cost(e.from, e.to)
+	}
+	return c
+}
+
+// flag notifies the planner of each edge whose cost changed from old.
+func (g *Grid) flag(edges []edge, old []float64) {
+	if g.planner == nil {
+		return
+	}
+	var changes []dstarlite.EdgeChange
+	for i, e := range edges {
+		cNew := g.Cost(e.from, e.to)
+		if cNew != old[i] {
+			changes = append(changes, dstarlite.EdgeChange{U: e.from, V: e.to, COld: old[i], CNew: cNew})
+		}
+	}
+	g.planner.FlagChangedBatch(changes)
+}
+
+// Size returns the width and height of the grid, in cells.
+func (g *Grid) Size() (width, height int) {
+	return g.width, g.height
+}
+
+// InBounds tells if the specified cell lies inside the grid.
+func (g *Grid) InBounds(c Coord) bool {
+	return c.X >= 0 && c.Y >= 0 && c.X < g.width && c.Y < g.height
+}
+
+// Blocked tells if the specified cell is blocked. Cells outside the grid are
+// always considered blocked.
+func (g *Grid) Blocked(c Coord) bool {
+	if !g.InBounds(c) {
+		return true
+	}
+	return g.blocked[c.Y*g.width+c.X]
+}
+
+// SetBlocked marks the specified cell as blocked or passable. Cells outside
+// the grid are ignored.
+func (g *Grid) SetBlocked(c Coord, blocked bool) {
+	if !g.InBounds(c) {
+		return
+	}
+	edges := g.cellEdges(c)
+	old := g.costs(edges)
+	g.blocked[c.Y*g.width+c.X] = blocked
+	g.flag(edges, old)
+}
+
+// BlockCells marks all the specified cells as blocked, e.g. when a building is
+// placed, and notifies the planner (if any) of every edge whose cost changed
+// in a single batch, such that one call to Plan reflects them all. Edges
+// shared by several of the cells are flagged only once. Cells outside the
+// grid are ignored.
+func (g *Grid) BlockCells(cells []Coord) {
+	g.setBlockedCells(cells, true)
+}
+
+// UnblockCells is like BlockCells, except that it marks the cells passable.
+func (g *Grid) UnblockCells(cells []Coord) {
+	g.setBlockedCells(cells, false)
+}
+
+// setBlockedCells implements BlockCells and UnblockCells.
+func (g *Grid) setBlockedCells(cells []Coord, blocked bool) {
+	var edges []edge
+	seen := make(map[edge]bool)
+	for _, c := range cells {
+		if !g.InBounds(c) {
+			continue
+		}
+		for _, e := range g.cellEdges(c) {
+			if !seen[e] {
+				seen[e] = true
+				edges = append(edges, e)
+			}
+		}
+	}
+	old := g.costs(edges)
+	for _, c := range cells {
+		if g.InBounds(c) {
+			g.blocked[c.Y*g.width+c.X] = blocked
+		}
+	}
+	g.flag(edges, old)
+}
+
+// AddDoor places a door between the two neighboring cells a and b, which
+// makes the edge between them asymmetric: moving from a to b costs costAtoB
+// and moving from b to a costs costBtoA. A door may for instance be cheap to
+// push open from one side but locked (expensive, or +Inf) from the other.
+//
+// Adding a door where one already exists replaces it, which can be used to
+// lock or unlock it dynamically. Moving into or out of a blocked cell costs
+// +Inf regardless of doors. Door costs below one make the Manhattan distance
+// heuristic inadmissible, and should be avoided.
+func (g *Grid) AddDoor(a, b Coord, costAtoB, costBtoA float64) {
+	if abs(a.X-b.X)+abs(a.Y-b.Y) != 1 || !g.InBounds(a) || !g.InBounds(b) {
+		panic("grid: door cells must be in-bounds neighbors")
+	}
+	edges := []edge{{a, b}, {b, a}}
+	old := g.costs(edges)
+	g.doors[edge{a, b}] = costAtoB
+	g.doors[edge{b, a}] = costBtoA
+	g.flag(edges, old)
+}
+
+// RemoveDoor removes the door between the two neighboring cells a and b, if
+// any.
+func (g *Grid) RemoveDoor(a, b Coord) {
+	edges := []edge{{a, b}, {b, a}}
+	old := g.costs(edges)
+	delete(g.doors, edge{a, b})
+	delete(g.doors, edge{b, a})
+	g.flag(edges, old)
+}
+
+// neighbors returns the in-bounds neighbors of the specified cell.
+func (g *Grid) neighbors(c Coord) []dstarlite.State {
+	n := make([]dstarlite.State, 0, 8)
+	add := func(nc Coord) {
+		nc = g.wrap(nc)
+		if !g.InBounds(nc) || nc == c {
+			return
+		}
+		if g.toroidal {
+			// In narrow grids several moves may wrap to the same cell.
+			for _, other := range n {
+				if other == nc {
+					return
+				}
+			}
+		}
+		n = append(n, nc)
+	}
+	for _, d := range directions {
+		add(Coord{c.X + d.X, c.Y + d.Y})
+	}
+	if g.diagonal {
+		for _, d := range diagonals {
+			add(Coord{c.X + d.X, c.Y + d.Y})
+		}
+	}
+	return n
+}
+
+// Succ implements the dstarlite.Data interface.
+//
+// Blocked neighbors are still returned (traversing to them simply has an
+// infinite cost) such that edge cost changes can be flagged to a planner.
+func (g *Grid) Succ(s dstarlite.State) []dstarlite.State {
+	return g.neighbors(s.(Coord))
+}
+
+// Pred implements the dstarlite.Data interface.
+func (g *Grid) Pred(s dstarlite.State) []dstarlite.State {
+	return g.neighbors(s.(Coord))
+}
+
+// Dist implements the dstarlite.Data interface. It returns the Manhattan
+// distance between the two cells, or the octile distance in 8-connected grids,
+// unless another heuristic is selected (see UseHeuristic).
+func (g *Grid) Dist(a, b dstarlite.State) float64 {
+	ac := a.(Coord)
+	bc := b.(Coord)
+	dx := abs(ac.X - bc.X)
+	dy := abs(ac.Y - bc.Y)
+	if g.toroidal {
+		if w := g.width - dx; w < dx {
+			dx = w
+		}
+		if h := g.height - dy; h < dy {
+			dy = h
+		}
+	}
+	d := g.distance(float64(dx), float64(dy))
+	if g.minWeight != 1 {
+		// Scaled down slightly, like the octile distance, as the sums of
+		// weighted costs are subject to floating point error.
+		d *= g.minWeight * (1 - 1e-9)
+	}
+	return d
+}
+
+// Cost implements the dstarlite.Data interface. Moving between two passable
+// neighboring cells costs the weight of the destination cell (one by default,
+// see Weights) or the cost of the door between them, moving into or out of a
+// blocked cell costs +Inf. In 8-connected grids diagonal moves cost Sqrt2
+// times the weight, or +Inf if they cut the corner of a blocked cell.
+func (g *Grid) Cost(a, b dstarlite.State) float64 {
+	ac := a.(Coord)
+	bc := b.(Coord)
+	if g.Blocked(ac) || g.Blocked(bc) {
+		return math.Inf(1)
+	}
+	if ac.X != bc.X && ac.Y != bc.Y {
+		if g.Blocked(g.wrap(Coord{ac.X, bc.Y})) || g.Blocked(g.wrap(Coord{bc.X, ac.Y})) {
+			return math.Inf(1)
+		}
+		return math.Sqrt2 * g.Weight(bc)
+	}
+	if c, ok := g.doors[edge{ac, bc}]; ok {
+		return c
+	}
+	return g.Weight(bc)
+}
+
+// New returns a new grid of the specified size, with every cell passable, and
+// applies the given options to it.
+func New(width, height int, opts ...Option) *Grid {
+	g := &Grid{
+		width:   width,
+		height:  height,
+		blocked: make([]bool, width*height),
+		doors:   make(map[edge]float64),
+
+		minWeight: 1,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
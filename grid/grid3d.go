@@ -0,0 +1,231 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// Coord3 represents a single voxel in a 3D grid. It implements the
+// dstarlite.State interface.
+type Coord3 struct {
+	X, Y, Z int
+}
+
+// Equals implements the dstarlite.State interface.
+func (c Coord3) Equals(other dstarlite.State) bool {
+	o, ok := other.(Coord3)
+	return ok && o == c
+}
+
+// Grid3D is a box of voxels, each either passable or blocked, for voxel based
+// pathfinding. It implements the dstarlite.Data interface.
+//
+// Voxels are either 6-connected (to the voxels sharing a face, at a cost of
+// one) or 26-connected (also to those sharing an edge or a corner, at a cost
+// equal to the Euclidean length of the move). Diagonal moves may not cut
+// corners, i.e. they are impassable if any voxel they pass by is blocked.
+type Grid3D struct {
+	width, height, depth int
+	connectivity         int
+	blocked              []bool
+	planner              *dstarlite.Planner
+}
+
+// SetPlanner sets the planner that the grid notifies (via FlagChanged) of
+// every edge cost change made through SetBlocked. Nil (the default) disables
+// notification.
+func (g *Grid3D) SetPlanner(p *dstarlite.Planner) {
+	g.planner = p
+}
+
+// Size returns the width, height and depth of the grid, in voxels.
+func (g *Grid3D) Size() (width, height, depth int) {
+	return g.width, g.height, g.depth
+}
+
+// InBounds tells if the specified voxel lies inside the grid.
+func (g *Grid3D) InBounds(c Coord3) bool {
+	return c.X >= 0 && c.Y >= 0 && c.Z >= 0 && c.X < g.width && c.Y < g.height && c.Z < g.depth
+}
+
+func (g *Grid3D) index(c Coord3) int {
+	return (c.Z*g.height+c.Y)*g.width + c.X
+}
+
+// Blocked tells if the specified voxel is blocked. Voxels outside the grid
+// are always considered blocked.
+func (g *Grid3D) Blocked(c Coord3) bool {
+	if !g.InBounds(c) {
+		return true
+	}
+	return g.blocked[g.index(c)]
+}
+
+// SetBlocked marks the specified voxel as blocked or passable, notifying the
+// planner (if any) of every edge whose cost changed. Voxels outside the grid
+// are ignored.
+func (g *Grid3D) SetBlocked(c Coord3, blocked bool) {
+	if !g.InBounds(c) {
+		return
+	}
+	if g.planner == nil {
+		g.blocked[g.index(c)] = blocked
+		return
+	}
+
+	// Every move whose bounding box contains c may change cost.
+	type edge3 struct {
+		from, to Coord3
+	}
+	var edges []edge3
+	for _, a := range g.around(c) {
+		for _, b := range g.around(a) {
+			if a != b && g.adjacent(a, b) && within(c, a, b) {
+				edges = append(edges, edge3{a, b})
+			}
+		}
+	}
+	old := make([]float64, len(edges))
+	for i, e := range edges {
+		old[i] = g.Cost(e.from, e.to)
+	}
+	g.blocked[g.index(c)] = blocked
+
+	var changes []dstarlite.EdgeChange
+	for i, e := range edges {
+		if cNew := g.Cost(e.from, e.to); cNew != old[i] {
+			changes = append(changes, dstarlite.EdgeChange{U: e.from, V: e.to, COld: old[i], CNew: cNew})
+		}
+	}
+	g.planner.FlagChangedBatch(changes)
+}
+
+// around returns the in-bounds voxels of the 3x3x3 box centered at c,
+// including c itself.
+func (g *Grid3D) around(c Coord3) []Coord3 {
+	n := make([]Coord3, 0, 27)
+	for z := -1; z <= 1; z++ {
+		for y := -1; y <= 1; y++ {
+			for x := -1; x <= 1; x++ {
+				nc := Coord3{c.X + x, c.Y + y, c.Z + z}
+				if g.InBounds(nc) {
+					n = append(n, nc)
+				}
+			}
+		}
+	}
+	return n
+}
+
+// adjacent tells if the distinct voxels a and b, which lie in each others
+// 3x3x3 box, are connected.
+func (g *Grid3D) adjacent(a, b Coord3) bool {
+	return g.connectivity == 26 || abs(a.X-b.X)+abs(a.Y-b.Y)+abs(a.Z-b.Z) == 1
+}
+
+// within tells if c lies in the bounding box of a and b.
+func within(c, a, b Coord3) bool {
+	in := func(v, a, b int) bool {
+		if a > b {
+			a, b = b, a
+		}
+		return v >= a && v <= b
+	}
+	return in(c.X, a.X, b.X) && in(c.Y, a.Y, b.Y) && in(c.Z, a.Z, b.Z)
+}
+
+// neighbors returns the in-bounds neighbors of the specified voxel.
+func (g *Grid3D) neighbors(c Coord3) []dstarlite.State {
+	n := make([]dstarlite.State, 0, g.connectivity)
+	for _, nc := range g.around(c) {
+		if nc != c && g.adjacent(c, nc) {
+			n = append(n, nc)
+		}
+	}
+	return n
+}
+
+// Succ implements the dstarlite.Data interface.
+//
+// Blocked neighbors are still returned (traversing to them simply has an
+// infinite cost) such that edge cost changes can be flagged to a planner.
+func (g *Grid3D) Succ(s dstarlite.State) []dstarlite.State {
+	return g.neighbors(s.(Coord3))
+}
+
+// Pred implements the dstarlite.Data interface.
+func (g *Grid3D) Pred(s dstarlite.State) []dstarlite.State {
+	return g.neighbors(s.(Coord3))
+}
+
+// Dist implements the dstarlite.Data interface. It returns the Manhattan
+// distance between the two voxels in 6-connected grids, and the Euclidean
+// distance in 26-connected ones.
+func (g *Grid3D) Dist(a, b dstarlite.State) float64 {
+	ac := a.(Coord3)
+	bc := b.(Coord3)
+	dx := float64(ac.X - bc.X)
+	dy := float64(ac.Y - bc.Y)
+	dz := float64(ac.Z - bc.Z)
+	if g.connectivity == 6 {
+		return math.Abs(dx) + math.Abs(dy) + math.Abs(dz)
+	}
+	// Scaled down slightly for floating point error, as in Grid.Dist.
+	return math.Sqrt(dx*dx+dy*dy+dz*dz) * (1 - 1e-9)
+}
+
+// Cost implements the dstarlite.Data interface. Moving between two neighboring
+// voxels costs the Euclidean length of the move, or +Inf if any voxel in the
+// bounding box of the move is blocked.
+func (g *Grid3D) Cost(a, b dstarlite.State) float64 {
+	ac := a.(Coord3)
+	bc := b.(Coord3)
+	x0, x1 := ac.X, bc.X
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	y0, y1 := ac.Y, bc.Y
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	z0, z1 := ac.Z, bc.Z
+	if z0 > z1 {
+		z0, z1 = z1, z0
+	}
+	for z := z0; z <= z1; z++ {
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				if g.Blocked(Coord3{x, y, z}) {
+					return math.Inf(1)
+				}
+			}
+		}
+	}
+	switch (x1 - x0) + (y1 - y0) + (z1 - z0) {
+	case 1:
+		return 1
+	case 2:
+		return math.Sqrt2
+	}
+	return math.Sqrt(3)
+}
+
+// New3D returns a new 3D grid of the specified size, with every voxel
+// passable. The connectivity must be either 6 or 26.
+func New3D(width, height, depth, connectivity int) *Grid3D {
+	if connectivity != 6 && connectivity != 26 {
+		panic("grid: connectivity must be 6 or 26")
+	}
+	return &Grid3D{
+		width:        width,
+		height:       height,
+		depth:        depth,
+		connectivity: connectivity,
+		blocked:      make([]bool, width*height*depth),
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestGrid3D(t *testing.T) {
+	corner := grid.Coord3{X: 3, Y: 3, Z: 3}
+	tests := []struct {
+		name         string
+		connectivity int
+		wall         bool // Whether the plane z == 1 is blocked, but for (3, 3, 1).
+		states       int
+		cost         float64
+	}{
+		{"6-connected", 6, false, 10, 9},
+		{"26-connected", 26, false, 4, 3 * math.Sqrt(3)},
+		{"6-connected through a hole", 6, true, 10, 9},
+		{"26-connected through a hole", 26, true, 7, 3 + 3*math.Sqrt2},
+	}
+	for _, tst := range tests {
+		g := grid.New3D(4, 4, 4, tst.connectivity)
+		if tst.wall {
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 4; x++ {
+					g.SetBlocked(grid.Coord3{X: x, Y: y, Z: 1}, x != 3 || y != 3)
+				}
+			}
+		}
+		path := dstarlite.New(g, grid.Coord3{}, corner).Plan()
+		if len(path) != tst.states {
+			t.Errorf("%s: got path %v, want %d states", tst.name, path, tst.states)
+			continue
+		}
+		if cost := pathCost(g, path); math.Abs(cost-tst.cost) > 1e-9 {
+			t.Errorf("%s: path %v costs %v, want %v", tst.name, path, cost, tst.cost)
+		}
+	}
+}
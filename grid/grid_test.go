@@ -0,0 +1,84 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestBlockCells(t *testing.T) {
+	g, start, goal := parseGrid(`
+S.......
+........
+........
+.......G
+`)
+	p := dstarlite.New(g, start, goal)
+	g.SetPlanner(p)
+	before := p.Plan()
+
+	// A building across the middle, overlapping the border of the grid.
+	var building []grid.Coord
+	for y := -1; y < 3; y++ {
+		for x := 3; x < 5; x++ {
+			building = append(building, grid.Coord{X: x, Y: y})
+		}
+	}
+	g.BlockCells(building)
+	for _, c := range building {
+		if g.InBounds(c) && !g.Blocked(c) {
+			t.Errorf("cell %v is not blocked", c)
+		}
+	}
+	want := dstarlite.New(g, start, goal).Plan()
+	if got := p.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after BlockCells planned %v, want %v", got, want)
+	}
+
+	g.UnblockCells(building)
+	if got := p.Plan(); !reflect.DeepEqual(got, before) {
+		t.Errorf("after UnblockCells planned %v, want %v", got, before)
+	}
+}
+
+func TestToroidal(t *testing.T) {
+	g, start, goal := parseGrid(`
+.....
+.###.
+S#.#G
+.###.
+.....
+`, grid.Toroidal())
+	if !g.Toroidal() {
+		t.Fatal("grid is not toroidal")
+	}
+	if d := g.Dist(start, goal); d != 1 {
+		t.Errorf("Dist(%v, %v) = %v, want 1 across the edge", start, goal, d)
+	}
+	want := []dstarlite.State{start, goal}
+	if path := dstarlite.New(g, start, goal).Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("got path %v, want %v", path, want)
+	}
+
+	// In a grid two cells wide, both horizontal moves lead to the same cell.
+	narrow := grid.New(2, 1, grid.Toroidal())
+	want = []dstarlite.State{grid.Coord{X: 1, Y: 0}}
+	if succ := narrow.Succ(grid.Coord{X: 0, Y: 0}); !reflect.DeepEqual(succ, want) {
+		t.Errorf("narrow grid: got successors %v, want %v", succ, want)
+	}
+
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := grid.Load(&buf); err != nil || !loaded.Toroidal() {
+		t.Errorf("loaded grid is not toroidal (error %v)", err)
+	}
+}
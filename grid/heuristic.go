@@ -0,0 +1,83 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+)
+
+// Heuristic selects the distance function that a grid returns from Dist, see
+// UseHeuristic.
+//
+// A heuristic is admissible (never overestimates the cost of moving between
+// two cells, which the planner requires to find optimal paths) if it is at
+// most the exact distance for the move set of the grid:
+//
+//  Heuristic  4-connected            8-connected
+//  Manhattan  exact                  overestimates
+//  Euclidean  admissible, weaker     admissible, weaker
+//  Chebyshev  admissible, weaker     admissible, weaker
+//  Octile     admissible, weaker     exact
+//
+// An overestimating heuristic is allowed, but the planner may then return
+// suboptimal paths. A weaker (lower) heuristic still yields optimal paths, but
+// makes the planner expand more cells.
+type Heuristic int
+
+const (
+	// Auto is Manhattan for 4-connected grids and Octile for 8-connected ones.
+	Auto Heuristic = iota
+
+	// Manhattan is the sum of the horizontal and vertical distances.
+	Manhattan
+
+	// Euclidean is the straight line distance.
+	Euclidean
+
+	// Chebyshev is the largest of the horizontal and vertical distances.
+	Chebyshev
+
+	// Octile is the cost of moving diagonally until level with the goal, then
+	// straight.
+	Octile
+)
+
+// UseHeuristic makes the grid return the given heuristic from Dist instead of
+// the default of Auto.
+func UseHeuristic(h Heuristic) Option {
+	return func(g *Grid) {
+		g.heuristic = h
+	}
+}
+
+// Heuristic returns the heuristic used by the grid, see UseHeuristic.
+func (g *Grid) Heuristic() Heuristic {
+	return g.heuristic
+}
+
+// distance returns the distance between two cells that are dx and dy apart
+// (both non-negative) under the heuristic of the grid.
+func (g *Grid) distance(dx, dy float64) float64 {
+	h := g.heuristic
+	if h == Auto {
+		h = Manhattan
+		if g.diagonal {
+			h = Octile
+		}
+	}
+	switch h {
+	case Euclidean:
+		return math.Hypot(dx, dy) * (1 - 1e-9)
+	case Chebyshev:
+		return math.Max(dx, dy)
+	case Octile:
+		// Scaled down slightly, as otherwise floating point error in the sums
+		// of Sqrt2 costs may make the distance overestimate them, which (by
+		// breaking ties between keys the wrong way) can stop the planner
+		// before the path is optimal.
+		return (math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)) * (1 - 1e-9)
+	}
+	return dx + dy
+}
@@ -0,0 +1,75 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestHeuristic(t *testing.T) {
+	m := `
+S.....
+.####.
+.#..#.
+.#.G#.
+......
+`
+	tests := []struct {
+		name string
+		h    grid.Heuristic
+		diag bool
+		dist float64 // From (0, 0) to (3, 4).
+	}{
+		{"auto", grid.Auto, false, 7},
+		{"auto diagonal", grid.Auto, true, 4 + 3*(math.Sqrt2-1)},
+		{"manhattan", grid.Manhattan, false, 7},
+		{"euclidean", grid.Euclidean, false, 5},
+		{"euclidean diagonal", grid.Euclidean, true, 5},
+		{"chebyshev", grid.Chebyshev, false, 4},
+		{"chebyshev diagonal", grid.Chebyshev, true, 4},
+		{"octile", grid.Octile, false, 4 + 3*(math.Sqrt2-1)},
+		{"octile diagonal", grid.Octile, true, 4 + 3*(math.Sqrt2-1)},
+	}
+	for _, tst := range tests {
+		opts := []grid.Option{grid.UseHeuristic(tst.h)}
+		if tst.diag {
+			opts = append(opts, grid.Diagonal())
+		}
+		g, start, goal := parseGrid(m, opts...)
+		if g.Heuristic() != tst.h {
+			t.Errorf("%s: Heuristic() = %v, want %v", tst.name, g.Heuristic(), tst.h)
+		}
+		if d := g.Dist(grid.Coord{X: 0, Y: 0}, grid.Coord{X: 3, Y: 4}); math.Abs(d-tst.dist) > 1e-6 {
+			t.Errorf("%s: Dist = %v, want %v", tst.name, d, tst.dist)
+		}
+
+		// Every heuristic above is admissible, so the path is as cheap as
+		// with the default one.
+		auto, _, _ := parseGrid(m, opts[1:]...)
+		want := dstarlite.New(auto, start, goal)
+		want.Plan()
+		p := dstarlite.New(g, start, goal)
+		if path := p.Plan(); path == nil || math.Abs(p.PathCost()-want.PathCost()) > 1e-6 {
+			t.Errorf("%s: got path %v of cost %v, want cost %v", tst.name, path, p.PathCost(), want.PathCost())
+		}
+
+		var buf bytes.Buffer
+		if err := g.Save(&buf); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := grid.Load(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Heuristic() != tst.h {
+			t.Errorf("%s: loaded grid with heuristic %v, want %v", tst.name, loaded.Heuristic(), tst.h)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// Hex represents a single tile of a hexagonal grid in axial coordinates. It
+// implements the dstarlite.State interface.
+//
+// Tiles are pointy-topped: Q grows to the east and R to the south-east, such
+// that the six neighbors of a tile differ by one of (+1, 0), (-1, 0), (0, +1),
+// (0, -1), (+1, -1) and (-1, +1).
+type Hex struct {
+	Q, R int
+}
+
+// Equals implements the dstarlite.State interface.
+func (h Hex) Equals(other dstarlite.State) bool {
+	o, ok := other.(Hex)
+	return ok && o == h
+}
+
+// hexDirections holds the axial offset to each of the six neighbors of a tile.
+var hexDirections = [...]Hex{{1, 0}, {1, -1}, {0, -1}, {-1, 0}, {-1, 1}, {0, 1}}
+
+// OffsetToHex returns the tile at the given column and row of an "odd-r"
+// offset layout, where odd rows are shoved half a tile to the east. This is
+// the layout in which HexGrid stores its tiles, and may be used to index
+// arrays of tiles.
+func OffsetToHex(col, row int) Hex {
+	return Hex{col - (row-(row&1))/2, row}
+}
+
+// Offset returns the column and row of the tile in the "odd-r" offset layout,
+// see OffsetToHex.
+func (h Hex) Offset() (col, row int) {
+	return h.Q + (h.R-(h.R&1))/2, h.R
+}
+
+// HexGrid is a rectangular map of hexagonal tiles, each either passable or
+// blocked. It implements the dstarlite.Data interface.
+//
+// The tiles are those with offset coordinates (see OffsetToHex) within the
+// width and height of the grid. Moving between two neighboring passable tiles
+// costs one.
+type HexGrid struct {
+	width, height int
+	blocked       []bool
+	planner       *dstarlite.Planner
+}
+
+// SetPlanner sets the planner that the grid notifies (via FlagChanged) of
+// every edge cost change made through SetBlocked. Nil (the default) disables
+// notification.
+func (g *HexGrid) SetPlanner(p *dstarlite.Planner) {
+	g.planner = p
+}
+
+// Size returns the width and height of the grid, in tiles.
+func (g *HexGrid) Size() (width, height int) {
+	return g.width, g.height
+}
+
+// InBounds tells if the specified tile lies inside the grid.
+func (g *HexGrid) InBounds(h Hex) bool {
+	col, row := h.Offset()
+	return col >= 0 && row >= 0 && col < g.width && row < g.height
+}
+
+func (g *HexGrid) index(h Hex) int {
+	col, row := h.Offset()
+	return row*g.width + col
+}
+
+// Blocked tells if the specified tile is blocked. Tiles outside the grid are
+// always considered blocked.
+func (g *HexGrid) Blocked(h Hex) bool {
+	if !g.InBounds(h) {
+		return true
+	}
+	return g.blocked[g.index(h)]
+}
+
+// SetBlocked marks the specified tile as blocked or passable, notifying the
+// planner (if any) of every edge whose cost changed. Tiles outside the grid
+// are ignored.
+func (g *HexGrid) SetBlocked(h Hex, blocked bool) {
+	if !g.InBounds(h) {
+		return
+	}
+	if g.planner == nil {
+		g.blocked[g.index(h)] = blocked
+		return
+	}
+
+	var changes []dstarlite.EdgeChange
+	neighbors := g.neighbors(h)
+	old := make([]float64, len(neighbors))
+	for i, n := range neighbors {
+		old[i] = g.Cost(h, n)
+	}
+	g.blocked[g.index(h)] = blocked
+	for i, n := range neighbors {
+		if cNew := g.Cost(h, n); cNew != old[i] {
+			changes = append(changes,
+				dstarlite.EdgeChange{U: h, V: n, COld: old[i], CNew: cNew},
+				dstarlite.EdgeChange{U: n, V: h, COld: old[i], CNew: cNew},
+			)
+		}
+	}
+	g.planner.FlagChangedBatch(changes)
+}
+
+// neighbors returns the in-bounds neighbors of the specified tile.
+func (g *HexGrid) neighbors(h Hex) []dstarlite.State {
+	n := make([]dstarlite.State, 0, len(hexDirections))
+	for _, d := range hexDirections {
+		nh := Hex{h.Q + d.Q, h.R + d.R}
+		if g.InBounds(nh) {
+			n = append(n, nh)
+		}
+	}
+	return n
+}
+
+// Succ implements the dstarlite.Data interface.
+//
+// Blocked neighbors are still returned (traversing to them simply has an
+// infinite cost) such that edge cost changes can be flagged to a planner.
+func (g *HexGrid) Succ(s dstarlite.State) []dstarlite.State {
+	return g.neighbors(s.(Hex))
+}
+
+// Pred implements the dstarlite.Data interface.
+func (g *HexGrid) Pred(s dstarlite.State) []dstarlite.State {
+	return g.neighbors(s.(Hex))
+}
+
+// Dist implements the dstarlite.Data interface. It returns the hex distance
+// between the two tiles, i.e. the number of moves between them on an open
+// grid.
+func (g *HexGrid) Dist(a, b dstarlite.State) float64 {
+	ah := a.(Hex)
+	bh := b.(Hex)
+	dq := ah.Q - bh.Q
+	dr := ah.R - bh.R
+	return float64(abs(dq)+abs(dr)+abs(dq+dr)) / 2
+}
+
+// Cost implements the dstarlite.Data interface. Moving between two passable
+// neighboring tiles costs one, moving into or out of a blocked tile costs
+// +Inf.
+func (g *HexGrid) Cost(a, b dstarlite.State) float64 {
+	if g.Blocked(a.(Hex)) || g.Blocked(b.(Hex)) {
+		return math.Inf(1)
+	}
+	return 1
+}
+
+// NewHex returns a new hexagonal grid of the specified size (in the "odd-r"
+// offset layout, see OffsetToHex), with every tile passable.
+func NewHex(width, height int) *HexGrid {
+	return &HexGrid{
+		width:   width,
+		height:  height,
+		blocked: make([]bool, width*height),
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestHexOffsetRoundTrip(t *testing.T) {
+	for row := -3; row < 4; row++ {
+		for col := -3; col < 4; col++ {
+			if c, r := grid.OffsetToHex(col, row).Offset(); c != col || r != row {
+				t.Errorf("offset (%d, %d) round-trips to (%d, %d)", col, row, c, r)
+			}
+		}
+	}
+}
+
+func TestHexGrid(t *testing.T) {
+	g := grid.NewHex(7, 7)
+	center := grid.OffsetToHex(3, 3)
+	if n := len(g.Succ(center)); n != 6 {
+		t.Errorf("center tile has %d neighbors, want 6", n)
+	}
+	if n := len(g.Succ(grid.OffsetToHex(0, 0))); n != 2 {
+		t.Errorf("corner tile has %d neighbors, want 2", n)
+	}
+
+	// On an open grid Dist is exact.
+	for row := 0; row < 7; row++ {
+		for col := 0; col < 7; col++ {
+			h := grid.OffsetToHex(col, row)
+			p := dstarlite.New(g, center, h)
+			if path := p.Plan(); float64(len(path)-1) != g.Dist(center, h) {
+				t.Errorf("path to %v has %d moves, Dist is %v", h, len(path)-1, g.Dist(center, h))
+			}
+		}
+	}
+
+	// Walling off a tile is flagged to the planner, which plans around it.
+	start, goal := grid.OffsetToHex(0, 3), grid.OffsetToHex(6, 3)
+	p := dstarlite.New(g, start, goal)
+	g.SetPlanner(p)
+	p.Plan()
+	for row := 1; row < 6; row++ {
+		g.SetBlocked(grid.OffsetToHex(3, row), true)
+	}
+	want := dstarlite.New(g, start, goal).Plan()
+	if got := p.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after blocking got path %v, want %v", got, want)
+	}
+	for _, st := range want {
+		if g.Blocked(st.(grid.Hex)) {
+			t.Errorf("path enters blocked tile %v", st)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// FromImage returns a new grid with one cell per pixel of the image, where the
+// cells of the pixels for which blocked returns true are blocked, and applies
+// the given options to it. This allows drawing maps in any paint program:
+//
+//  f, _ := os.Open("map.png")
+//  img, _ := png.Decode(f)
+//  g, err := grid.FromImage(img, func(c color.Color) bool {
+//      r, g, b, _ := c.RGBA()
+//      return r+g+b < 3*0x8000 // Dark pixels are walls.
+//  })
+//
+// The cell of the pixel at img.Bounds().Min is Coord{0, 0}. An error is
+// returned if the image is empty.
+func FromImage(img image.Image, blocked func(color.Color) bool, opts ...Option) (*Grid, error) {
+	b := img.Bounds()
+	if b.Empty() {
+		return nil, errors.New("grid: empty image")
+	}
+	g := New(b.Dx(), b.Dy(), opts...)
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			g.blocked[y*g.width+x] = blocked(img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return g, nil
+}
+
+// DrawPath plots each state of the path (which must be Coords, e.g. as planned
+// through a grid returned by FromImage) onto the image in the given color, at
+// the pixel that the cell was created from: the cell Coord{0, 0} maps to the
+// pixel at img.Bounds().Min. States that are not Coords, or that fall outside
+// the image, are skipped.
+func DrawPath(img draw.Image, path []dstarlite.State, c color.Color) {
+	b := img.Bounds()
+	for _, st := range path {
+		cell, ok := st.(Coord)
+		if !ok {
+			continue
+		}
+		p := image.Pt(b.Min.X+cell.X, b.Min.Y+cell.Y)
+		if p.In(b) {
+			img.Set(p.X, p.Y, c)
+		}
+	}
+}
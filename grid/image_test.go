@@ -0,0 +1,72 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestFromImage(t *testing.T) {
+	// An image whose bounds do not start at the origin, with a black pixel in
+	// its second column of its first row.
+	img := image.NewGray(image.Rect(5, 7, 8, 9))
+	for y := 7; y < 9; y++ {
+		for x := 5; x < 8; x++ {
+			img.SetGray(x, y, color.Gray{0xff})
+		}
+	}
+	img.SetGray(6, 7, color.Gray{0})
+	dark := func(c color.Color) bool {
+		r, g, b, _ := c.RGBA()
+		return r+g+b < 3*0x8000
+	}
+	g, err := grid.FromImage(img, dark, grid.Diagonal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.InBounds(grid.Coord{X: 2, Y: 1}) || g.InBounds(grid.Coord{X: 3, Y: 0}) || g.InBounds(grid.Coord{X: 0, Y: 2}) {
+		t.Errorf("grid does not have the 3 by 2 cells of the image")
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			c := grid.Coord{X: x, Y: y}
+			if want := x == 1 && y == 0; g.Blocked(c) != want {
+				t.Errorf("cell %v blocked is %v, want %v", c, g.Blocked(c), want)
+			}
+		}
+	}
+	if len(g.Succ(grid.Coord{X: 0, Y: 1})) != 3 {
+		t.Errorf("options not applied: cell {0 1} has successors %v", g.Succ(grid.Coord{X: 0, Y: 1}))
+	}
+
+	if _, err := grid.FromImage(image.NewGray(image.Rect(0, 0, 0, 3)), dark); err == nil {
+		t.Error("empty image: got no error")
+	}
+}
+
+func TestDrawPath(t *testing.T) {
+	img := image.NewGray(image.Rect(5, 7, 8, 9))
+	g, err := grid.FromImage(img, func(color.Color) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 2, Y: 0}).Plan()
+
+	// Cells outside the image and states other than coordinates are skipped.
+	path = append(path, grid.Coord{X: 3, Y: 0}, grid.Coord{X: -1, Y: 0}, nil)
+	grid.DrawPath(img, path, color.Gray{0xff})
+	for y := 7; y < 9; y++ {
+		for x := 5; x < 8; x++ {
+			if want := y == 7; (img.GrayAt(x, y).Y == 0xff) != want {
+				t.Errorf("pixel (%d, %d) drawn is %v, want %v", x, y, !want, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// MinInfluenceCost is the lowest fraction of its cost on the grid that
+// InfluenceCost lowers any move to.
+const MinInfluenceCost = 0.1
+
+type influenceCost struct {
+	*Grid
+	influence func(x, y int) float64
+	weight    float64
+}
+
+// InfluenceCost returns a view of the grid g for tactical pathing, in which
+// the cost of entering each cell is lowered by weight times the influence of
+// the cell (e.g. how strongly it is controlled by friendly units), steering
+// paths through high-influence areas. Negative influence (hostile territory)
+// raises the cost instead.
+//
+// Lowered costs are clamped to MinInfluenceCost times the cost of the move on
+// g, so every move keeps a positive cost and the gradient walk performed by
+// Plan always terminates. To stay admissible (also for diagonal moves and
+// weighted cells) the distance heuristic is scaled down by the same factor,
+// which makes the planner expand more states. Paths are optimal with respect
+// to the adjusted costs, and not the original ones.
+func InfluenceCost(g *Grid, influence func(x, y int) float64, weight float64) dstarlite.Data {
+	return &influenceCost{g, influence, weight}
+}
+
+// Dist implements the dstarlite.Data interface.
+func (i *influenceCost) Dist(a, b dstarlite.State) float64 {
+	return i.Grid.Dist(a, b) * MinInfluenceCost
+}
+
+// Cost implements the dstarlite.Data interface.
+func (i *influenceCost) Cost(a, b dstarlite.State) float64 {
+	c := i.Grid.Cost(a, b)
+	if math.IsInf(c, 1) {
+		return c
+	}
+	bc := b.(Coord)
+	return math.Max(MinInfluenceCost*c, c-i.weight*i.influence(bc.X, bc.Y))
+}
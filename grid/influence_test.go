@@ -0,0 +1,58 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestInfluenceCostDetours(t *testing.T) {
+	// The top row is held by friendly units, the middle row (the direct route
+	// from start to goal) by hostile ones.
+	influence := func(x, y int) float64 {
+		switch y {
+		case 0:
+			return 1
+		case 2:
+			return -1
+		}
+		return 0
+	}
+	g := grid.New(7, 5)
+	start, goal := grid.Coord{X: 0, Y: 2}, grid.Coord{X: 6, Y: 2}
+
+	tests := []struct {
+		name     string
+		weight   float64
+		friendly bool // Whether the path passes through the top row.
+	}{
+		{"no influence", 0, false},
+		{"influence", 1, true},
+	}
+	for _, tst := range tests {
+		d := grid.InfluenceCost(g, influence, tst.weight)
+		path := dstarlite.New(d, start, goal).Plan()
+		if len(path) == 0 {
+			t.Fatalf("%s: no path", tst.name)
+		}
+		friendly := false
+		for i, s := range path {
+			if s.(grid.Coord).Y == 0 {
+				friendly = true
+			}
+			if i > 0 {
+				if c := d.Cost(path[i-1], s); c < grid.MinInfluenceCost {
+					t.Errorf("%s: move to %v costs %v, below the minimum", tst.name, s, c)
+				}
+			}
+		}
+		if friendly != tst.friendly {
+			t.Errorf("%s: path %v through friendly cells: %v, want %v", tst.name, path, friendly, tst.friendly)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// JumpData plans through a grid using long straight moves, inspired by the
+// pruning of Jump Point Search. It implements the dstarlite.Data interface.
+//
+// Instead of its four neighbors, the successors of a cell are, in each of the
+// four directions, the nearest cell at which a straight move in that direction
+// must stop (a jump point). Moving along direction d, a cell c is a jump point
+// if any of the following hold:
+//
+//  c is the start or the goal.
+//  c has a forced neighbor: for either side perpendicular to d, the side
+//  neighbor of c is blocked while that of the previous cell is not, or vice
+//  versa (i.e. c lies at the corner of an obstacle).
+//  d is horizontal, and a vertical move from c would reach a jump point.
+//
+// Moves that run into an obstacle or the edge of the grid without finding a
+// jump point are pruned, as the path can be found without them.
+//
+// A path can only turn at jump points, so on open maps the planner expands
+// far fewer states than on the plain grid while producing paths of equal cost.
+// The cost of a jump is the sum of the grid costs of its single steps.
+//
+// The pruning rules assume that every step costs the same: a cheaper or more
+// expensive step would be a reason to stop (or turn) that they do not detect,
+// so paths would no longer be optimal. For this reason jump point views
+// require an unweighted grid, without any doors (see NewJump).
+//
+// Since a single change to the grid (or the start) can move the jump points of
+// many cells, edge cost changes are not tracked: after modifying the grid,
+// create a new view and planner.
+type JumpData struct {
+	g           *Grid
+	start, goal Coord
+}
+
+// stop tells if moving along dir must stop at c.
+func (d *JumpData) stop(c, dir Coord) bool {
+	if c == d.goal || c == d.start {
+		return true
+	}
+	prev := Coord{c.X - dir.X, c.Y - dir.Y}
+	for _, p := range [...]Coord{{dir.Y, dir.X}, {-dir.Y, -dir.X}} {
+		side := d.g.Blocked(Coord{c.X + p.X, c.Y + p.Y})
+		prevSide := d.g.Blocked(Coord{prev.X + p.X, prev.Y + p.Y})
+		if side != prevSide {
+			return true
+		}
+	}
+	if dir.Y == 0 {
+		// Horizontal moves also stop wherever a vertical move would find a
+		// jump point.
+		for _, v := range [...]Coord{{0, 1}, {0, -1}} {
+			if _, ok := d.jump(c, v); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jump returns the jump point reached by moving from c along dir, if any.
+func (d *JumpData) jump(c, dir Coord) (Coord, bool) {
+	for {
+		c = Coord{c.X + dir.X, c.Y + dir.Y}
+		if d.g.Blocked(c) {
+			return c, false
+		}
+		if d.stop(c, dir) {
+			return c, true
+		}
+	}
+}
+
+// Succ implements the dstarlite.Data interface.
+func (d *JumpData) Succ(s dstarlite.State) []dstarlite.State {
+	c := s.(Coord)
+	var succ []dstarlite.State
+	for _, dir := range directions {
+		if j, ok := d.jump(c, dir); ok {
+			succ = append(succ, j)
+		}
+	}
+	return succ
+}
+
+// turn tells if c is a jump point in any direction, i.e. whether a path from
+// the start may ever arrive at (and hence continue from) c.
+func (d *JumpData) turn(c Coord) bool {
+	for _, dir := range directions {
+		prev := Coord{c.X - dir.X, c.Y - dir.Y}
+		if !d.g.Blocked(prev) && d.stop(c, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pred implements the dstarlite.Data interface. Only the cells that a path
+// from the start can actually pass through are returned.
+func (d *JumpData) Pred(s dstarlite.State) []dstarlite.State {
+	c := s.(Coord)
+	if d.g.Blocked(c) {
+		return nil
+	}
+	var pred []dstarlite.State
+	for _, dir := range directions {
+		if !d.stop(c, dir) {
+			continue
+		}
+		// Every cell behind c up to (and including) the previous jump point
+		// jumps to c.
+		p := c
+		for {
+			p = Coord{p.X - dir.X, p.Y - dir.Y}
+			if d.g.Blocked(p) {
+				break
+			}
+			if d.turn(p) {
+				pred = append(pred, p)
+			}
+			if d.stop(p, dir) {
+				break
+			}
+		}
+	}
+	return pred
+}
+
+// Dist implements the dstarlite.Data interface. It is the Manhattan distance
+// between the two cells.
+func (d *JumpData) Dist(a, b dstarlite.State) float64 {
+	return d.g.Dist(a, b)
+}
+
+// Cost implements the dstarlite.Data interface. The cost between two cells
+// that are not connected by a single jump is +Inf.
+func (d *JumpData) Cost(a, b dstarlite.State) float64 {
+	ac := a.(Coord)
+	bc := b.(Coord)
+	if ac == bc || (ac.X != bc.X && ac.Y != bc.Y) {
+		return math.Inf(1)
+	}
+	dir := Coord{sign(bc.X - ac.X), sign(bc.Y - ac.Y)}
+	if j, ok := d.jump(ac, dir); !ok || j != bc {
+		return math.Inf(1)
+	}
+	var cost float64
+	for c := ac; c != bc; {
+		next := Coord{c.X + dir.X, c.Y + dir.Y}
+		cost += d.g.Cost(c, next)
+		c = next
+	}
+	return cost
+}
+
+// NewJump returns a new jump point view of the grid g for planning from the
+// given start cell to the given goal cell. The view is specific to the start:
+// to plan from another start, create a new view and planner.
+//
+// NewJump panics if the grid has doors or cell weights (given by the Weights
+// option or SetWeight), see JumpData.
+func NewJump(g *Grid, start, goal Coord) *JumpData {
+	if len(g.doors) > 0 {
+		panic("grid: jump point views require a grid without doors")
+	}
+	if g.weights != nil {
+		panic("grid: jump point views require a grid without weights")
+	}
+	return &JumpData{g: g, start: start, goal: goal}
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
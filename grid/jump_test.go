@@ -0,0 +1,85 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// pathCost returns the cost of the path through d.
+func pathCost(d dstarlite.Data, path []dstarlite.State) float64 {
+	var cost float64
+	for i := 1; i < len(path); i++ {
+		cost += d.Cost(path[i-1], path[i])
+	}
+	return cost
+}
+
+func TestJumpFewerExpansions(t *testing.T) {
+	const size = 64
+	tests := []struct {
+		name string
+		wall int // Length of a wall down the middle column.
+	}{
+		{"open", 0},
+		{"wall", size - 8},
+	}
+	for _, tst := range tests {
+		g := grid.New(size, size)
+		start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: size - 1, Y: size - 1}
+		for y := 0; y < tst.wall; y++ {
+			g.SetBlocked(grid.Coord{X: size / 2, Y: y}, true)
+		}
+
+		plain := dstarlite.New(g, start, goal)
+		want := plain.Plan()
+		if want == nil {
+			t.Fatalf("%s: no path on the plain grid", tst.name)
+		}
+		j := grid.NewJump(g, start, goal)
+		jp := dstarlite.New(j, start, goal)
+		path := jp.Plan()
+		if got, want := pathCost(j, path), pathCost(g, want); got != want {
+			t.Errorf("%s: jump path %v costs %v, want %v", tst.name, path, got, want)
+		}
+		if got, max := jp.LastExpansions(), plain.LastExpansions()/4; got > max {
+			t.Errorf("%s: jump planner expanded %d states, want at most %d (plain grid %d)", tst.name, got, max, plain.LastExpansions())
+		}
+	}
+}
+
+func TestJumpRequiresUniformCosts(t *testing.T) {
+	tests := []struct {
+		name string
+		g    func() *grid.Grid
+	}{
+		{"doors", func() *grid.Grid {
+			g := grid.New(4, 4)
+			g.AddDoor(grid.Coord{X: 1, Y: 1}, grid.Coord{X: 1, Y: 2}, 1, 5)
+			return g
+		}},
+		{"weights option", func() *grid.Grid {
+			return grid.New(2, 1, grid.Weights([][]float64{{1, 3}}))
+		}},
+		{"weight set", func() *grid.Grid {
+			g := grid.New(4, 4)
+			g.SetWeight(grid.Coord{X: 2, Y: 2}, 3)
+			return g
+		}},
+	}
+	for _, tst := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: NewJump did not panic", tst.name)
+				}
+			}()
+			grid.NewJump(tst.g(), grid.Coord{X: 0, Y: 0}, grid.Coord{X: 1, Y: 0})
+		}()
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"azul3d.org/dstarlite.v1"
+)
+
+// LineOfSight returns a line of sight predicate for the grid g, for use with
+// dstarlite.Smooth. It reports whether the straight line between the centers
+// of two cells crosses only passable cells; where the line passes exactly
+// through the corner of two cells, both must be passable.
+func LineOfSight(g *Grid) func(a, b dstarlite.State) bool {
+	return func(a, b dstarlite.State) bool {
+		return g.lineOfSight(a.(Coord), b.(Coord))
+	}
+}
+
+func (g *Grid) lineOfSight(a, b Coord) bool {
+	dx, dy := abs(b.X-a.X), abs(b.Y-a.Y)
+	sx, sy := sign(b.X-a.X), sign(b.Y-a.Y)
+	x, y := a.X, a.Y
+	err := dx - dy
+	dx *= 2
+	dy *= 2
+	for n := dx/2 + dy/2; n > 0; n-- {
+		if g.Blocked(Coord{x, y}) {
+			return false
+		}
+		switch {
+		case err > 0:
+			x += sx
+			err -= dy
+		case err < 0:
+			y += sy
+			err += dx
+		default:
+			// The line passes through a corner.
+			if g.Blocked(Coord{x + sx, y}) || g.Blocked(Coord{x, y + sy}) {
+				return false
+			}
+			x += sx
+			y += sy
+			err += dx - dy
+			n--
+		}
+	}
+	return !g.Blocked(b)
+}
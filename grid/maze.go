@@ -0,0 +1,65 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math/rand"
+)
+
+// GenerateMaze returns a new grid of the specified size holding a maze, e.g.
+// as a reproducible fixture for tests and benchmarks, and applies the given
+// options to it. The maze is generated by a randomized depth-first search (the
+// "recursive backtracker") seeded by seed, so equal arguments always yield the
+// same maze.
+//
+// The passages of the maze are the cells with even coordinates and the cells
+// carved between them, so every passable cell can be reached from every other,
+// in particular from Coord{0, 0} to the opposite corner Coord{width-1,
+// height-1}. Passages are one cell wide, with a single route between any two
+// cells. The width and height must be positive.
+func GenerateMaze(width, height int, seed int64, opts ...Option) *Grid {
+	if width <= 0 || height <= 0 {
+		panic("grid: maze size must be positive")
+	}
+	g := New(width, height, opts...)
+	for i := range g.blocked {
+		g.blocked[i] = true
+	}
+	open := func(c Coord) {
+		g.blocked[c.Y*g.width+c.X] = false
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	open(Coord{0, 0})
+	stack := []Coord{{0, 0}}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+
+		// Pick a random unvisited cell two steps away, if any.
+		var next []Coord
+		for _, d := range directions {
+			n := Coord{c.X + 2*d.X, c.Y + 2*d.Y}
+			if g.InBounds(n) && g.Blocked(n) {
+				next = append(next, n)
+			}
+		}
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		n := next[r.Intn(len(next))]
+		open(Coord{(c.X + n.X) / 2, (c.Y + n.Y) / 2})
+		open(n)
+		stack = append(stack, n)
+	}
+
+	// With an even width or height the far corner is not a maze cell, so
+	// connect it to the nearest one.
+	corner := Coord{width - 1, height - 1}
+	nearest := Coord{corner.X &^ 1, corner.Y &^ 1}
+	open(Coord{corner.X, nearest.Y})
+	open(corner)
+	return g
+}
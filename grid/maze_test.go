@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestGenerateMaze(t *testing.T) {
+	for _, size := range [][2]int{{1, 1}, {9, 9}, {10, 7}, {7, 10}, {12, 12}} {
+		w, h := size[0], size[1]
+		g := grid.GenerateMaze(w, h, 1)
+		if !reflect.DeepEqual(g, grid.GenerateMaze(w, h, 1)) {
+			t.Errorf("%dx%d: equal seeds yield different mazes", w, h)
+		}
+
+		// The passable cells form a tree, so there is a single route between
+		// any two of them.
+		cells, edges := 0, 0
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				c := grid.Coord{X: x, Y: y}
+				if g.Blocked(c) {
+					continue
+				}
+				cells++
+				for _, n := range []grid.Coord{{X: x + 1, Y: y}, {X: x, Y: y + 1}} {
+					if g.InBounds(n) && !g.Blocked(n) {
+						edges++
+					}
+				}
+			}
+		}
+		if edges != cells-1 {
+			t.Errorf("%dx%d: %d passable cells joined by %d edges, want a tree", w, h, cells, edges)
+		}
+		corner := grid.Coord{X: w - 1, Y: h - 1}
+		if path := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, corner).Plan(); path == nil {
+			t.Errorf("%dx%d: no path between the corners", w, h)
+		}
+	}
+	if reflect.DeepEqual(grid.GenerateMaze(15, 15, 1), grid.GenerateMaze(15, 15, 2)) {
+		t.Error("different seeds yield equal mazes")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// PathDeltas returns the relative (dx, dy) moves between each consecutive
+// pair of cells in the path, which must consist of Coord states. The result
+// has one element less than the path (or is nil for paths shorter than two).
+//
+// Deltas are a compact, position-independent form of a path suitable for
+// replays or sending over a network, see ApplyDeltas for the inverse.
+func PathDeltas(path []dstarlite.State) [][2]int {
+	if len(path) < 2 {
+		return nil
+	}
+	deltas := make([][2]int, len(path)-1)
+	prev := path[0].(Coord)
+	for i, s := range path[1:] {
+		c := s.(Coord)
+		deltas[i] = [2]int{c.X - prev.X, c.Y - prev.Y}
+		prev = c
+	}
+	return deltas
+}
+
+// ApplyDeltas reconstructs a path from the start cell (which must be a Coord)
+// and the relative moves, as returned by PathDeltas. The returned path begins
+// with start.
+func ApplyDeltas(start dstarlite.State, deltas [][2]int) []dstarlite.State {
+	c := start.(Coord)
+	path := make([]dstarlite.State, 0, len(deltas)+1)
+	path = append(path, c)
+	for _, d := range deltas {
+		c = Coord{c.X + d[0], c.Y + d[1]}
+		path = append(path, c)
+	}
+	return path
+}
+
+// PathSmoothness measures how smooth a path of Coord states is. It returns
+// the number of direction changes along the path, and the sum of the turning
+// angles (in radians, each between zero and Pi) at those changes. A straight
+// path has zero turns, a zig-zag path has many.
+//
+// Steps that do not move (e.g. waiting in place) are ignored.
+func PathSmoothness(path []dstarlite.State) (turns int, totalAngle float64) {
+	var (
+		prev    float64
+		hasPrev bool
+	)
+	for _, d := range PathDeltas(path) {
+		if d[0] == 0 && d[1] == 0 {
+			continue
+		}
+		dir := math.Atan2(float64(d[1]), float64(d[0]))
+		if hasPrev {
+			angle := math.Abs(dir - prev)
+			if angle > math.Pi {
+				angle = 2*math.Pi - angle
+			}
+			if angle > 1e-9 {
+				turns++
+				totalAngle += angle
+			}
+		}
+		prev = dir
+		hasPrev = true
+	}
+	return
+}
+
+// PathChokepoint returns the state of the path with the fewest passable
+// neighbors in d (successors that can be moved to at a finite cost), and that
+// count; i.e. the narrowest point that the path passes through, such as a one
+// cell wide gap in a grid. Among states with equal counts the earliest one is
+// returned. For an empty path it returns nil and zero.
+func PathChokepoint(d dstarlite.Data, path []dstarlite.State) (dstarlite.State, int) {
+	var (
+		choke  dstarlite.State
+		fewest int
+	)
+	for _, s := range path {
+		free := 0
+		for _, n := range d.Succ(s) {
+			if !math.IsInf(d.Cost(s, n), 1) {
+				free++
+			}
+		}
+		if choke == nil || free < fewest {
+			choke = s
+			fewest = free
+		}
+	}
+	return choke, fewest
+}
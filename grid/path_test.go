@@ -0,0 +1,150 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// parseGrid returns the grid of a text map with one line per row, in which
+// '#' marks blocked cells and 'S' and 'G' the start and goal cells. Leading
+// and trailing newlines are ignored. The grid is created with the given
+// options.
+func parseGrid(m string, opts ...grid.Option) (g *grid.Grid, start, goal grid.Coord) {
+	rows := strings.Split(strings.Trim(m, "\n"), "\n")
+	g = grid.New(len(rows[0]), len(rows), opts...)
+	for y, row := range rows {
+		for x, r := range row {
+			c := grid.Coord{X: x, Y: y}
+			switch r {
+			case '#':
+				g.SetBlocked(c, true)
+			case 'S':
+				start = c
+			case 'G':
+				goal = c
+			}
+		}
+	}
+	return g, start, goal
+}
+
+func TestPathDeltasRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		m    string
+	}{
+		{"straight", "S....G"},
+		{"winding", `
+S.#.....
+#.#.###.
+..#.#...
+.##.#.##
+....#..G
+`},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		path := dstarlite.New(g, start, goal).Plan()
+		if len(path) < 2 {
+			t.Fatalf("%s: got path %v, want one to the goal", tst.name, path)
+		}
+		deltas := grid.PathDeltas(path)
+		if len(deltas) != len(path)-1 {
+			t.Fatalf("%s: got %d deltas for %d states", tst.name, len(deltas), len(path))
+		}
+		if got := grid.ApplyDeltas(path[0], deltas); !reflect.DeepEqual(got, path) {
+			t.Errorf("%s: ApplyDeltas returned %v, want %v", tst.name, got, path)
+		}
+	}
+}
+
+func TestPathDeltasShort(t *testing.T) {
+	start := grid.Coord{X: 3, Y: 4}
+	for _, path := range [][]dstarlite.State{nil, {start}} {
+		if d := grid.PathDeltas(path); d != nil {
+			t.Errorf("PathDeltas(%v) = %v, want nil", path, d)
+		}
+	}
+	want := []dstarlite.State{start}
+	if got := grid.ApplyDeltas(start, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyDeltas(%v, nil) = %v, want %v", start, got, want)
+	}
+}
+
+func TestPathSmoothness(t *testing.T) {
+	coords := func(cs ...[2]int) []dstarlite.State {
+		path := make([]dstarlite.State, len(cs))
+		for i, c := range cs {
+			path[i] = grid.Coord{X: c[0], Y: c[1]}
+		}
+		return path
+	}
+	tests := []struct {
+		name  string
+		path  []dstarlite.State
+		turns int
+		angle float64
+	}{
+		{"empty", nil, 0, 0},
+		{"straight", coords([2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{3, 0}, [2]int{4, 0}), 0, 0},
+		{"one corner", coords([2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{2, 1}, [2]int{2, 2}), 1, math.Pi / 2},
+		{"zig-zag", coords([2]int{0, 0}, [2]int{1, 0}, [2]int{1, 1}, [2]int{2, 1}, [2]int{2, 2}), 3, 3 * math.Pi / 2},
+		{"wait", coords([2]int{0, 0}, [2]int{1, 0}, [2]int{1, 0}, [2]int{2, 0}), 0, 0},
+		{"reverse", coords([2]int{0, 0}, [2]int{1, 0}, [2]int{0, 0}), 1, math.Pi},
+	}
+	for _, tst := range tests {
+		turns, angle := grid.PathSmoothness(tst.path)
+		if turns != tst.turns || math.Abs(angle-tst.angle) > 1e-9 {
+			t.Errorf("%s: got %d turns and angle %v, want %d and %v", tst.name, turns, angle, tst.turns, tst.angle)
+		}
+	}
+
+	// The straight path must rank as smoother than the zig-zag one.
+	zt, za := grid.PathSmoothness(tests[3].path)
+	st, sa := grid.PathSmoothness(tests[1].path)
+	if st >= zt || sa >= za {
+		t.Errorf("straight path (%d, %v) is not smoother than zig-zag (%d, %v)", st, sa, zt, za)
+	}
+}
+
+func TestPathChokepoint(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     string
+		choke grid.Coord
+		free  int
+	}{
+		{"one-wide gap", `
+..#..
+..#..
+S...G
+..#..
+..#..
+`, grid.Coord{X: 2, Y: 2}, 2},
+		{"open", `
+.....
+S...G
+.....
+`, grid.Coord{X: 0, Y: 1}, 3},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		path := dstarlite.New(g, start, goal).Plan()
+		choke, free := grid.PathChokepoint(g, path)
+		if choke != tst.choke || free != tst.free {
+			t.Errorf("%s: got chokepoint %v with %d free neighbors, want %v with %d", tst.name, choke, free, tst.choke, tst.free)
+		}
+	}
+	if choke, free := grid.PathChokepoint(grid.New(2, 2), nil); choke != nil || free != 0 {
+		t.Errorf("empty path: got chokepoint %v with %d free neighbors, want nil and 0", choke, free)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// fileMagic identifies the binary grid format written by Save.
+var fileMagic = [4]byte{'D', 'S', 'L', 'G'}
+
+// fileVersion is the version of the binary grid format written by Save, and
+// the only one that Load reads.
+const fileVersion = 3
+
+// Bits of the flags byte. Bits 1 to 3 hold the heuristic of the grid.
+const (
+	flagDiagonal       = 1 << 0
+	flagHeuristicShift = 1
+	flagHeuristicMask  = 7 << flagHeuristicShift
+	flagToroidal       = 1 << 4
+	flagWeighted       = 1 << 5
+)
+
+// ErrFormat is returned by Load when the data is not a grid written by Save.
+var ErrFormat = errors.New("grid: invalid grid data")
+
+// maxLoadCells limits the number of cells of a grid read by Load, such that
+// corrupt data cannot make it allocate huge amounts of memory.
+const maxLoadCells = 1 << 26
+
+// Save writes the grid (its dimensions, options, blocked cells, and doors) to w
+// in a versioned binary format, which Load can read back. The attached
+// planner, if any, is not saved.
+//
+// The format is little-endian: the magic bytes "DSLG", a uint16 version, the
+// uint32 width and height, a flags byte (bit 0 is set for 8-connected grids,
+// bits 1 to 3 hold the Heuristic, bit 4 is set for toroidal grids and bit 5
+// if the cells have weights), one byte per cell (row by row) that is one for
+// blocked cells, a uint32 door count followed by each door as four int32
+// coordinates (from X, Y and to X, Y) and its float64 cost, and the float64
+// minimum weight followed (if bit 5 is set) by the float64 weight of each
+// cell, row by row. Doors are written in order of their coordinates, such that
+// equal grids are always saved as equal bytes.
+func (g *Grid) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+	binary.Write(bw, le, fileMagic)
+	binary.Write(bw, le, uint16(fileVersion))
+	binary.Write(bw, le, [2]uint32{uint32(g.width), uint32(g.height)})
+	var flags byte
+	if g.diagonal {
+		flags |= flagDiagonal
+	}
+	flags |= byte(g.heuristic) << flagHeuristicShift
+	if g.toroidal {
+		flags |= flagToroidal
+	}
+	if g.weights != nil {
+		flags |= flagWeighted
+	}
+	bw.WriteByte(flags)
+	for _, b := range g.blocked {
+		var v byte
+		if b {
+			v = 1
+		}
+		bw.WriteByte(v)
+	}
+	binary.Write(bw, le, uint32(len(g.doors)))
+	for _, e := range g.sortedDoors() {
+		binary.Write(bw, le, [4]int32{int32(e.from.X), int32(e.from.Y), int32(e.to.X), int32(e.to.Y)})
+		binary.Write(bw, le, g.doors[e])
+	}
+	binary.Write(bw, le, g.minWeight)
+	if g.weights != nil {
+		binary.Write(bw, le, g.weights)
+	}
+	return bw.Flush()
+}
+
+// sortedDoors returns the edges of the doors of the grid, ordered by the
+// coordinates of the cells they lead from and then to.
+func (g *Grid) sortedDoors() []edge {
+	edges := make([]edge, 0, len(g.doors))
+	for e := range g.doors {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.from != b.from {
+			return a.from.Y < b.from.Y || a.from.Y == b.from.Y && a.from.X < b.from.X
+		}
+		return a.to.Y < b.to.Y || a.to.Y == b.to.Y && a.to.X < b.to.X
+	})
+	return edges
+}
+
+// Load reads a grid, as written by Save, from r. Data that is not a valid
+// grid, including one with more than 1<<26 cells, yields ErrFormat.
+func Load(r io.Reader) (*Grid, error) {
+	br := bufio.NewReader(r)
+	le := binary.LittleEndian
+
+	var magic [4]byte
+	if err := binary.Read(br, le, &magic); err != nil {
+		return nil, err
+	}
+	if magic != fileMagic {
+		return nil, ErrFormat
+	}
+	var version uint16
+	if err := binary.Read(br, le, &version); err != nil {
+		return nil, err
+	}
+	if version != fileVersion {
+		return nil, fmt.Errorf("grid: unsupported grid format version %d", version)
+	}
+
+	var size [2]uint32
+	if err := binary.Read(br, le, &size); err != nil {
+		return nil, err
+	}
+	w, h := uint64(size[0]), uint64(size[1])
+	if w > maxLoadCells || h > maxLoadCells || w*h > maxLoadCells {
+		return nil, ErrFormat
+	}
+	g := New(int(size[0]), int(size[1]))
+	flags, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	g.diagonal = flags&flagDiagonal != 0
+	g.toroidal = flags&flagToroidal != 0
+	g.heuristic = Heuristic(flags&flagHeuristicMask) >> flagHeuristicShift
+	if g.heuristic > Octile {
+		return nil, ErrFormat
+	}
+	for i := range g.blocked {
+		v, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		g.blocked[i] = v != 0
+	}
+
+	var doors uint32
+	if err := binary.Read(br, le, &doors); err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < doors; i++ {
+		var c [4]int32
+		var cost float64
+		if err := binary.Read(br, le, &c); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(br, le, &cost); err != nil {
+			return nil, err
+		}
+		from := Coord{int(c[0]), int(c[1])}
+		to := Coord{int(c[2]), int(c[3])}
+		if !g.InBounds(from) || !g.InBounds(to) {
+			return nil, ErrFormat
+		}
+		g.doors[edge{from, to}] = cost
+	}
+
+	if err := binary.Read(br, le, &g.minWeight); err != nil {
+		return nil, err
+	}
+	if !(g.minWeight > 0) {
+		return nil, ErrFormat
+	}
+	if flags&flagWeighted != 0 {
+		g.initWeights()
+		if err := binary.Read(br, le, g.weights); err != nil {
+			return nil, err
+		}
+		for _, w := range g.weights {
+			if !(w >= g.minWeight) {
+				return nil, ErrFormat
+			}
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	g, start, goal := parseGrid(`
+S..#....
+.#.#.##.
+.#...#..
+.####.#.
+......#G
+`)
+	g.AddDoor(grid.Coord{X: 4, Y: 2}, grid.Coord{X: 4, Y: 1}, 1, 50)
+	g.AddDoor(grid.Coord{X: 7, Y: 2}, grid.Coord{X: 7, Y: 3}, 3, 1)
+
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.Bytes()
+	loaded, err := grid.Load(bytes.NewReader(saved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, g) {
+		t.Errorf("loaded grid %+v, want %+v", loaded, g)
+	}
+	var again bytes.Buffer
+	if err := loaded.Save(&again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Bytes(), saved) {
+		t.Error("saving the loaded grid yields different bytes")
+	}
+	want := dstarlite.New(g, start, goal).Plan()
+	if got := dstarlite.New(loaded, start, goal).Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded grid planned %v, want %v", got, want)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := grid.New(3, 2).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	// withSize returns the valid data with its dimensions replaced.
+	withSize := func(w, h uint32) []byte {
+		b := append([]byte(nil), valid...)
+		binary.LittleEndian.PutUint32(b[6:], w)
+		binary.LittleEndian.PutUint32(b[10:], h)
+		return b
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad magic", append([]byte("XXXX"), valid[4:]...)},
+		{"huge width", withSize(1<<31, 1)},
+		{"huge height", withSize(1, 0xffffffff)},
+		{"overflowing area", withSize(1<<16, 1<<16)},
+		{"unknown heuristic", append(append(valid[:14:14], 7<<1), valid[15:]...)},
+		{"zero minimum weight", append(valid[:len(valid)-8:len(valid)-8], make([]byte, 8)...)},
+	}
+	for _, tst := range tests {
+		if _, err := grid.Load(bytes.NewReader(tst.data)); err != grid.ErrFormat {
+			t.Errorf("%s: got error %v, want ErrFormat", tst.name, err)
+		}
+	}
+	if _, err := grid.Load(bytes.NewReader(valid[:len(valid)-1])); err == nil {
+		t.Error("truncated data: got no error")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// AnyPhase is the phase of the goal state returned by ScheduledData.Goal, it
+// represents the goal cell regardless of the time at which it is reached.
+const AnyPhase = -1
+
+// TimedCoord is a grid cell at a specific time phase. It implements the
+// dstarlite.State interface.
+type TimedCoord struct {
+	Coord
+	Phase int
+}
+
+// Equals implements the dstarlite.State interface.
+func (c TimedCoord) Equals(other dstarlite.State) bool {
+	o, ok := other.(TimedCoord)
+	return ok && o == c
+}
+
+// ScheduledData plans through a grid whose cells may hold periodic hazards,
+// like a trap that is on for two ticks and off for two. It implements the
+// dstarlite.Data interface.
+//
+// Each step (including waiting in place) takes one tick, and a cell is
+// impassable during the ticks in which its hazard is on. To represent this
+// the grid is expanded over time: states are TimedCoord's, such that each cell
+// exists once per phase of the schedule period. The state space is thus
+// width*height*period states large, and the planner may touch all of them,
+// so keep the period short.
+//
+// Paths returned by the planner start at a TimedCoord with the current phase
+// and end at the goal returned by the Goal method.
+type ScheduledData struct {
+	g       *Grid
+	period  int
+	goal    Coord
+	hazards map[Coord][]bool
+}
+
+// Goal returns the goal state that should be passed to dstarlite.New, it is
+// the goal cell at AnyPhase.
+func (d *ScheduledData) Goal() TimedCoord {
+	return TimedCoord{d.goal, AnyPhase}
+}
+
+// SetHazard sets the schedule of the hazard in the specified cell. The i-th
+// element of on tells whether the hazard is on during phase i, it must have a
+// length equal to the period. A nil schedule removes the hazard.
+func (d *ScheduledData) SetHazard(c Coord, on []bool) {
+	if on == nil {
+		delete(d.hazards, c)
+		return
+	}
+	if len(on) != d.period {
+		panic("grid: hazard schedule length must equal the period")
+	}
+	d.hazards[c] = on
+}
+
+// Hazardous tells if the specified cell is impassable at the given phase.
+func (d *ScheduledData) Hazardous(c Coord, phase int) bool {
+	on, ok := d.hazards[c]
+	if !ok {
+		return false
+	}
+	return on[phase%d.period]
+}
+
+func (d *ScheduledData) step(c TimedCoord) []dstarlite.State {
+	next := (c.Phase + 1) % d.period
+	n := []dstarlite.State{TimedCoord{c.Coord, next}}
+	for _, s := range d.g.neighbors(c.Coord) {
+		n = append(n, TimedCoord{s.(Coord), next})
+	}
+	if c.Coord == d.goal {
+		n = append(n, d.Goal())
+	}
+	return n
+}
+
+// Succ implements the dstarlite.Data interface. Waiting in the same cell for
+// one tick is always a successor.
+func (d *ScheduledData) Succ(s dstarlite.State) []dstarlite.State {
+	c := s.(TimedCoord)
+	if c.Phase == AnyPhase {
+		return nil
+	}
+	return d.step(c)
+}
+
+// Pred implements the dstarlite.Data interface.
+func (d *ScheduledData) Pred(s dstarlite.State) []dstarlite.State {
+	c := s.(TimedCoord)
+	if c.Phase == AnyPhase {
+		p := make([]dstarlite.State, d.period)
+		for i := range p {
+			p[i] = TimedCoord{c.Coord, i}
+		}
+		return p
+	}
+	prev := (c.Phase + d.period - 1) % d.period
+	p := []dstarlite.State{TimedCoord{c.Coord, prev}}
+	for _, s := range d.g.neighbors(c.Coord) {
+		p = append(p, TimedCoord{s.(Coord), prev})
+	}
+	return p
+}
+
+// Dist implements the dstarlite.Data interface. It is the Manhattan distance
+// between the two cells, ignoring phase.
+func (d *ScheduledData) Dist(a, b dstarlite.State) float64 {
+	return d.g.Dist(a.(TimedCoord).Coord, b.(TimedCoord).Coord)
+}
+
+// Cost implements the dstarlite.Data interface. Entering a cell whose hazard
+// is on at the arrival phase costs +Inf, reaching the goal state from the goal
+// cell is free.
+func (d *ScheduledData) Cost(a, b dstarlite.State) float64 {
+	ac := a.(TimedCoord)
+	bc := b.(TimedCoord)
+	if bc.Phase == AnyPhase {
+		return 0
+	}
+	if d.Hazardous(bc.Coord, bc.Phase) {
+		return math.Inf(1)
+	}
+	return d.g.Cost(ac.Coord, bc.Coord)
+}
+
+// NewScheduled returns a new time-expanded view of the grid g with the given
+// schedule period (in ticks) and goal cell. No cell holds a hazard initially.
+func NewScheduled(g *Grid, period int, goal Coord) *ScheduledData {
+	if period < 1 {
+		panic("grid: schedule period must be at least one")
+	}
+	return &ScheduledData{
+		g:       g,
+		period:  period,
+		goal:    goal,
+		hazards: make(map[Coord][]bool),
+	}
+}
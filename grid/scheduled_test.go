@@ -0,0 +1,60 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestScheduledWaitsForTrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		trap  []bool // Schedule of the trap at (1, 0), with period 4.
+		waits int    // -1 if there is no path.
+	}{
+		{"no trap", nil, 0},
+		{"off on arrival", []bool{true, false, true, true}, 0},
+		{"on for two ticks", []bool{false, true, true, false}, 2},
+		{"on for three ticks", []bool{false, true, true, true}, 3},
+		{"always on", []bool{true, true, true, true}, -1},
+	}
+	for _, tst := range tests {
+		// A corridor, such that the trap cannot be avoided by a detour.
+		d := grid.NewScheduled(grid.New(4, 1), 4, grid.Coord{X: 3, Y: 0})
+		d.SetHazard(grid.Coord{X: 1, Y: 0}, tst.trap)
+		start := grid.TimedCoord{Coord: grid.Coord{X: 0, Y: 0}, Phase: 0}
+		path := dstarlite.New(d, start, d.Goal()).Plan()
+		if tst.waits < 0 {
+			if path != nil {
+				t.Errorf("%s: got path %v, want nil", tst.name, path)
+			}
+			continue
+		}
+
+		// Three moves, the waits, and the final step to the goal state.
+		if want := 3 + tst.waits + 2; len(path) != want {
+			t.Fatalf("%s: path %v has %d states, want %d", tst.name, path, len(path), want)
+		}
+		if !path[len(path)-1].Equals(d.Goal()) {
+			t.Errorf("%s: path ends at %v, want %v", tst.name, path[len(path)-1], d.Goal())
+		}
+		waits := 0
+		for i, s := range path[1 : len(path)-1] {
+			prev, c := path[i].(grid.TimedCoord), s.(grid.TimedCoord)
+			if c.Coord == prev.Coord {
+				waits++
+			}
+			if d.Hazardous(c.Coord, c.Phase) {
+				t.Errorf("%s: path enters hazard at %v", tst.name, c)
+			}
+		}
+		if waits != tst.waits {
+			t.Errorf("%s: path %v waits %d ticks, want %d", tst.name, path, waits, tst.waits)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// Parse returns a new grid read from a text map, where each line is a row of
+// cells and each byte a cell, and applies the given options to it. Cells
+// marked by the wall byte are blocked, the single cells marked by the start
+// and goal bytes are returned as the start and goal states, and every other
+// byte is a passable cell. Leading and trailing newlines are ignored, so maps
+// may be written as raw string literals:
+//
+//  g, start, goal, err := grid.Parse(`
+//  S..#
+//  .#.#
+//  ...G
+//  `, '#', 'S', 'G')
+//  p := dstarlite.New(g, start, goal)
+//
+// An error is returned if the rows differ in length (ignoring carriage
+// returns at their end), or if there is not exactly one start and one goal.
+func Parse(s string, wall, start, goal byte, opts ...Option) (g *Grid, startState, goalState dstarlite.State, err error) {
+	rows := strings.Split(strings.Trim(s, "\r\n"), "\n")
+	for i, row := range rows {
+		rows[i] = strings.TrimSuffix(row, "\r")
+		if len(rows[i]) != len(rows[0]) {
+			return nil, nil, nil, fmt.Errorf("grid: row %d has length %d, want %d", i, len(rows[i]), len(rows[0]))
+		}
+	}
+	if len(rows[0]) == 0 {
+		return nil, nil, nil, errors.New("grid: empty map")
+	}
+
+	g = New(len(rows[0]), len(rows), opts...)
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			c := Coord{x, y}
+			switch row[x] {
+			case wall:
+				g.blocked[y*g.width+x] = true
+			case start:
+				if startState != nil {
+					return nil, nil, nil, fmt.Errorf("grid: second start at %v", c)
+				}
+				startState = c
+			case goal:
+				if goalState != nil {
+					return nil, nil, nil, fmt.Errorf("grid: second goal at %v", c)
+				}
+				goalState = c
+			}
+		}
+	}
+	if startState == nil {
+		return nil, nil, nil, errors.New("grid: map has no start")
+	}
+	if goalState == nil {
+		return nil, nil, nil, errors.New("grid: map has no goal")
+	}
+	return g, startState, goalState, nil
+}
+
+// Render returns a text map of the grid with the path drawn onto it, e.g. for
+// readable test failures: blocked cells are '#', passable cells '.', the cells
+// of the path '*', except for its first cell 'S' and last cell 'G'. Each row
+// ends with a newline, such that (given a path of two or more states) the
+// result can be read back by Parse. The path may be empty or nil; its states
+// that are not Coords, or that lie outside the grid, are skipped.
+func Render(g *Grid, path []dstarlite.State) string {
+	cells := make([]byte, len(g.blocked))
+	for i, b := range g.blocked {
+		cells[i] = '.'
+		if b {
+			cells[i] = '#'
+		}
+	}
+	for i, st := range path {
+		c, ok := st.(Coord)
+		if !ok || !g.InBounds(c) {
+			continue
+		}
+		mark := byte('*')
+		switch i {
+		case 0:
+			mark = 'S'
+		case len(path) - 1:
+			mark = 'G'
+		}
+		cells[c.Y*g.width+c.X] = mark
+	}
+
+	var b strings.Builder
+	for y := 0; y < g.height; y++ {
+		b.Write(cells[y*g.width : (y+1)*g.width])
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
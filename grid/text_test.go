@@ -0,0 +1,79 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestParse(t *testing.T) {
+	g, start, goal, err := grid.Parse("\r\nA.x\r\n..B\r\nxx.\r\n", 'x', 'A', 'B', grid.Diagonal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != (grid.Coord{X: 0, Y: 0}) || goal != (grid.Coord{X: 2, Y: 1}) {
+		t.Errorf("got start %v and goal %v, want {0 0} and {2 1}", start, goal)
+	}
+	if w, h := g.Size(); w != 3 || h != 3 {
+		t.Errorf("got size %dx%d, want 3x3", w, h)
+	}
+	for _, c := range []grid.Coord{{X: 2, Y: 0}, {X: 0, Y: 2}, {X: 1, Y: 2}} {
+		if !g.Blocked(c) {
+			t.Errorf("cell %v is not blocked", c)
+		}
+	}
+	if path := dstarlite.New(g, start, goal).Plan(); len(path) != 3 {
+		t.Errorf("got path %v, want a diagonal one of 3 states", path)
+	}
+
+	tests := []struct {
+		name, m string
+	}{
+		{"ragged rows", "S..\n.G"},
+		{"empty", "\n\n"},
+		{"no start", "..G"},
+		{"no goal", "S.."},
+		{"two starts", "S.S\n..G"},
+		{"two goals", "S.G\n..G"},
+	}
+	for _, tst := range tests {
+		if g, _, _, err := grid.Parse(tst.m, '#', 'S', 'G'); err == nil || g != nil {
+			t.Errorf("%s: got grid %v and error %v, want an error", tst.name, g, err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	m := `
+S..#
+.#.#
+...G
+`
+	g, start, goal := parseGrid(m)
+	path := dstarlite.New(g, start, goal).Plan()
+	got := grid.Render(g, path)
+	want := `
+S**#
+.#*#
+..*G
+`[1:]
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// The rendering reads back as the same map.
+	h, s, gl, err := grid.Parse(got, '#', 'S', 'G')
+	if err != nil || s != start || gl != goal || grid.Render(h, path) != got {
+		t.Errorf("rendering parsed as start %v and goal %v (error %v), want %v and %v", s, gl, err, start, goal)
+	}
+
+	// States that are not in the grid are skipped.
+	if got, want := grid.Render(g, []dstarlite.State{grid.Coord{X: 9, Y: 9}, nil}), "...#\n.#.#\n....\n"; got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"azul3d.org/dstarlite.v1"
+)
+
+// NoHeading is the heading of a TurnState that has not moved yet.
+const NoHeading = -1
+
+// TurnState is a grid cell reached while moving in a specific heading (an
+// index into the four directions +X, +Y, -X, -Y, or NoHeading) after having
+// made a number of turns. It implements the dstarlite.State interface.
+type TurnState struct {
+	Coord
+	Heading, Turns int
+}
+
+// Equals implements the dstarlite.State interface.
+func (t TurnState) Equals(other dstarlite.State) bool {
+	o, ok := other.(TurnState)
+	return ok && o == t
+}
+
+// TurnLimitedData plans through a grid using at most a fixed number of
+// direction changes (turns), e.g. for laser beams or vehicles with limited
+// steering. It implements the dstarlite.Data interface.
+//
+// States are TurnState's: the first move from the start (which should have
+// NoHeading and zero turns) is free to choose any heading, and every later
+// change of heading counts as a turn. To represent this each cell exists once
+// per heading and turn count, so the state space is width*height*4*(K+1)
+// states large for a limit of K turns, and grows linearly with K.
+//
+// Paths end at the goal state returned by the Goal method, which represents
+// the goal cell regardless of the heading and turns it is reached with.
+type TurnLimitedData struct {
+	g        *Grid
+	maxTurns int
+	goal     Coord
+}
+
+// Goal returns the goal state that should be passed to dstarlite.New.
+func (d *TurnLimitedData) Goal() TurnState {
+	return TurnState{d.goal, NoHeading, -1}
+}
+
+// Start returns the start state for the specified cell, which should be
+// passed to dstarlite.New.
+func (d *TurnLimitedData) Start(c Coord) TurnState {
+	return TurnState{c, NoHeading, 0}
+}
+
+func (d *TurnLimitedData) isGoal(t TurnState) bool {
+	return t.Turns < 0
+}
+
+// Succ implements the dstarlite.Data interface.
+func (d *TurnLimitedData) Succ(s dstarlite.State) []dstarlite.State {
+	t := s.(TurnState)
+	if d.isGoal(t) {
+		return nil
+	}
+	var succ []dstarlite.State
+	for h, dir := range directions {
+		n := Coord{t.X + dir.X, t.Y + dir.Y}
+		if !d.g.InBounds(n) {
+			continue
+		}
+		turns := t.Turns
+		if t.Heading != NoHeading && t.Heading != h {
+			turns++
+		}
+		if turns <= d.maxTurns {
+			succ = append(succ, TurnState{n, h, turns})
+		}
+	}
+	if t.Coord == d.goal {
+		succ = append(succ, d.Goal())
+	}
+	return succ
+}
+
+// Pred implements the dstarlite.Data interface.
+func (d *TurnLimitedData) Pred(s dstarlite.State) []dstarlite.State {
+	t := s.(TurnState)
+	var pred []dstarlite.State
+	if d.isGoal(t) {
+		for turns := 0; turns <= d.maxTurns; turns++ {
+			for h := NoHeading; h < len(directions); h++ {
+				if h == NoHeading && turns > 0 {
+					continue
+				}
+				pred = append(pred, TurnState{t.Coord, h, turns})
+			}
+		}
+		return pred
+	}
+	if t.Heading == NoHeading {
+		// Only start states have no heading.
+		return nil
+	}
+	dir := directions[t.Heading]
+	p := Coord{t.X - dir.X, t.Y - dir.Y}
+	if !d.g.InBounds(p) {
+		return nil
+	}
+	if t.Turns == 0 {
+		pred = append(pred, TurnState{p, NoHeading, 0})
+	}
+	for h := range directions {
+		if h == t.Heading {
+			pred = append(pred, TurnState{p, h, t.Turns})
+		} else if t.Turns > 0 {
+			pred = append(pred, TurnState{p, h, t.Turns - 1})
+		}
+	}
+	return pred
+}
+
+// Dist implements the dstarlite.Data interface. It is the Manhattan distance
+// between the two cells.
+func (d *TurnLimitedData) Dist(a, b dstarlite.State) float64 {
+	return d.g.Dist(a.(TurnState).Coord, b.(TurnState).Coord)
+}
+
+// Cost implements the dstarlite.Data interface. Reaching the goal state from
+// the goal cell is free.
+func (d *TurnLimitedData) Cost(a, b dstarlite.State) float64 {
+	bt := b.(TurnState)
+	if d.isGoal(bt) {
+		return 0
+	}
+	return d.g.Cost(a.(TurnState).Coord, bt.Coord)
+}
+
+// NewTurnLimited returns a new view of the grid g for planning paths towards
+// the goal cell with at most maxTurns turns.
+func NewTurnLimited(g *Grid, maxTurns int, goal Coord) *TurnLimitedData {
+	if maxTurns < 0 {
+		panic("grid: turn limit must be non-negative")
+	}
+	return &TurnLimitedData{
+		g:        g,
+		maxTurns: maxTurns,
+		goal:     goal,
+	}
+}
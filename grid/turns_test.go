@@ -0,0 +1,58 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestTurnLimited(t *testing.T) {
+	lShape := `
+S...
+###.
+...G
+`
+	tests := []struct {
+		name     string
+		m        string
+		maxTurns int
+		cells    int // Cells on the path, or zero if there is none.
+	}{
+		{"straight, no turns", "S..G", 0, 4},
+		{"L-shape, no turns", lShape, 0, 0},
+		{"L-shape, one turn", lShape, 1, 6},
+		{"L-shape, two turns", lShape, 2, 6},
+		{"open, one turn", "S...\n....\n....\n...G", 1, 7},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		d := grid.NewTurnLimited(g, tst.maxTurns, goal)
+		path := dstarlite.New(d, d.Start(start), d.Goal()).Plan()
+		if tst.cells == 0 {
+			if path != nil {
+				t.Errorf("%s: got path %v, want nil", tst.name, path)
+			}
+			continue
+		}
+		if len(path) == 0 || !path[len(path)-1].Equals(d.Goal()) {
+			t.Fatalf("%s: path %v does not end at the goal state", tst.name, path)
+		}
+
+		// Strip the goal state, and check the cells against the turn limit.
+		cells := make([]dstarlite.State, len(path)-1)
+		for i, s := range path[:len(path)-1] {
+			cells[i] = s.(grid.TurnState).Coord
+		}
+		if len(cells) != tst.cells {
+			t.Errorf("%s: path %v has %d cells, want %d", tst.name, cells, len(cells), tst.cells)
+		}
+		if turns, _ := grid.PathSmoothness(cells); turns > tst.maxTurns {
+			t.Errorf("%s: path %v makes %d turns, want at most %d", tst.name, cells, turns, tst.maxTurns)
+		}
+	}
+}
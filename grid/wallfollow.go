@@ -0,0 +1,59 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+type wallFollow struct {
+	*Grid
+	bonus float64
+}
+
+// WallFollowCost returns a view of the grid g in which entering a cell that
+// is adjacent to an obstacle (a blocked cell, or the edge of the grid) costs
+// the fraction bonusNearWall less than on g, such that paths hug walls, like
+// a scout following the perimeter of a room.
+//
+// The bonus is clamped to [0, 0.99] so that every cost stays positive. As no
+// move then costs less than 1-bonus times its cost on g (whatever the weights
+// of the cells), the distance heuristic is scaled down by 1-bonus to stay
+// admissible. Paths are optimal with respect to the discounted costs, so under
+// the original costs they may be up to 1/(1-bonus) times longer than the
+// shortest path; small bonuses keep paths near-optimal, large ones follow
+// walls more eagerly (and make the planner expand more states).
+func WallFollowCost(g *Grid, bonusNearWall float64) dstarlite.Data {
+	return &wallFollow{g, math.Max(0, math.Min(bonusNearWall, 0.99))}
+}
+
+// nearWall tells if any of the eight cells around c is blocked or outside the
+// grid.
+func (w *wallFollow) nearWall(c Coord) bool {
+	for y := -1; y <= 1; y++ {
+		for x := -1; x <= 1; x++ {
+			if (x != 0 || y != 0) && w.Blocked(Coord{c.X + x, c.Y + y}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Dist implements the dstarlite.Data interface.
+func (w *wallFollow) Dist(a, b dstarlite.State) float64 {
+	return w.Grid.Dist(a, b) * (1 - w.bonus)
+}
+
+// Cost implements the dstarlite.Data interface.
+func (w *wallFollow) Cost(a, b dstarlite.State) float64 {
+	c := w.Grid.Cost(a, b)
+	if math.IsInf(c, 1) || !w.nearWall(b.(Coord)) {
+		return c
+	}
+	return c * (1 - w.bonus)
+}
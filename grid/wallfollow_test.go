@@ -0,0 +1,50 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestWallFollowHugsPerimeter(t *testing.T) {
+	const size = 9
+	g := grid.New(size, size)
+	onPerimeter := func(c grid.Coord) bool {
+		return c.X == 0 || c.Y == 0 || c.X == size-1 || c.Y == size-1
+	}
+	start, goal := grid.Coord{X: 0, Y: size / 2}, grid.Coord{X: size - 1, Y: size - 1}
+
+	tests := []struct {
+		name string
+		d    dstarlite.Data
+		hugs bool
+	}{
+		{"no bonus", g, false},
+		{"bonus", grid.WallFollowCost(g, 0.1), true},
+	}
+	for _, tst := range tests {
+		path := dstarlite.New(tst.d, start, goal).Plan()
+		if len(path) == 0 {
+			t.Fatalf("%s: no path", tst.name)
+		}
+		hugs := true
+		for _, s := range path {
+			if !onPerimeter(s.(grid.Coord)) {
+				hugs = false
+			}
+		}
+		if hugs != tst.hugs {
+			t.Errorf("%s: path %v hugs the perimeter: %v, want %v", tst.name, path, hugs, tst.hugs)
+		}
+		// Every monotone path is a shortest one, so hugging the walls costs
+		// nothing here.
+		if want := size/2 + size; len(path) != want {
+			t.Errorf("%s: path has %d states, want %d", tst.name, len(path), want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"math"
+)
+
+// Weights sets the weight of each cell of the grid (w[y][x] for the cell
+// Coord{x, y}), for weighted terrain: moving into a cell costs its weight
+// (times Sqrt2 for diagonal moves), e.g. 3 for mud and 0.5 for a road. The
+// weights must be positive and w must have the size of the grid. Cells
+// default to a weight of one.
+//
+// Dist is scaled by the minimum weight (see MinWeight), which the option
+// lowers to the smallest of the given weights if needed, such that it stays
+// admissible. The views of the grid returned by WithFootprint,
+// WallFollowCost and InfluenceCost account for the weights as well.
+func Weights(w [][]float64) Option {
+	return func(g *Grid) {
+		if len(w) != g.height {
+			panic("grid: weights do not match the grid size")
+		}
+		g.initWeights()
+		for y, row := range w {
+			if len(row) != g.width {
+				panic("grid: weights do not match the grid size")
+			}
+			for x, weight := range row {
+				checkWeight(weight)
+				g.weights[y*g.width+x] = weight
+				g.minWeight = math.Min(g.minWeight, weight)
+			}
+		}
+	}
+}
+
+// MinWeight lowers the minimum weight of the grid, by which Dist is scaled, to
+// at most w (it is one by default). Since weights below the minimum would make
+// Dist inadmissible, SetWeight refuses them, so the option should be given
+// the smallest weight that cells may ever be set to.
+func MinWeight(w float64) Option {
+	return func(g *Grid) {
+		checkWeight(w)
+		g.minWeight = math.Min(g.minWeight, w)
+	}
+}
+
+// checkWeight panics if w is not a valid cell weight.
+func checkWeight(w float64) {
+	if !(w > 0) || math.IsInf(w, 1) {
+		panic("grid: weights must be positive and finite")
+	}
+}
+
+// initWeights allocates the weights of the grid, if needed.
+func (g *Grid) initWeights() {
+	if g.weights != nil {
+		return
+	}
+	g.weights = make([]float64, g.width*g.height)
+	for i := range g.weights {
+		g.weights[i] = 1
+	}
+}
+
+// MinWeight returns the minimum weight of the grid, see the MinWeight option.
+func (g *Grid) MinWeight() float64 {
+	return g.minWeight
+}
+
+// Weight returns the weight of the specified cell, i.e. the cost of moving
+// into it (see Weights). Cells outside the grid have a weight of +Inf.
+func (g *Grid) Weight(c Coord) float64 {
+	if !g.InBounds(c) {
+		return math.Inf(1)
+	}
+	if g.weights == nil {
+		return 1
+	}
+	return g.weights[c.Y*g.width+c.X]
+}
+
+// SetWeight sets the weight of the specified cell, notifying the planner (if
+// any) of every edge whose cost changed. It panics if the weight is below the
+// minimum weight of the grid (see the MinWeight option). Cells outside the
+// grid are ignored.
+func (g *Grid) SetWeight(c Coord, w float64) {
+	if !g.InBounds(c) {
+		return
+	}
+	checkWeight(w)
+	if w < g.minWeight {
+		panic("grid: weight below the minimum weight")
+	}
+	g.initWeights()
+	edges := g.cellEdges(c)
+	old := g.costs(edges)
+	g.weights[c.Y*g.width+c.X] = w
+	g.flag(edges, old)
+}
@@ -0,0 +1,104 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestWeightsDetour(t *testing.T) {
+	// A strip of mud across the middle row, with a road along the bottom.
+	w := [][]float64{
+		{1, 1, 1, 1, 1},
+		{1, 5, 5, 5, 1},
+		{0.5, 0.5, 0.5, 0.5, 0.5},
+	}
+	g := grid.New(5, 3, grid.Weights(w))
+	if g.MinWeight() != 0.5 {
+		t.Errorf("MinWeight() = %v, want 0.5", g.MinWeight())
+	}
+	start, goal := grid.Coord{X: 0, Y: 1}, grid.Coord{X: 4, Y: 1}
+	p := dstarlite.New(g, start, goal)
+	g.SetPlanner(p)
+	path := p.Plan()
+	if cost := p.PathCost(); cost != 3.5 {
+		t.Errorf("got path %v of cost %v, want one along the road of cost 3.5", path, cost)
+	}
+
+	// Paving the mud is flagged to the planner, which then crosses it.
+	for x := 1; x < 4; x++ {
+		g.SetWeight(grid.Coord{X: x, Y: 1}, 0.5)
+	}
+	want := dstarlite.New(g, start, goal).Plan()
+	if got := p.Plan(); !reflect.DeepEqual(got, want) || p.PathCost() != 2.5 {
+		t.Errorf("after paving got path %v of cost %v, want %v of cost 2.5", got, p.PathCost(), want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SetWeight below the minimum weight did not panic")
+		}
+	}()
+	g.SetWeight(grid.Coord{X: 0, Y: 0}, 0.25)
+}
+
+func TestWeightsAdmissible(t *testing.T) {
+	w := make([][]float64, 9)
+	for y := range w {
+		w[y] = make([]float64, 9)
+		for x := range w[y] {
+			w[y][x] = 0.5 + float64((x*7+y*3)%5)
+		}
+	}
+	goal := grid.Coord{X: 8, Y: 8}
+	tests := []struct {
+		name string
+		d    func(g *grid.Grid) dstarlite.Data
+	}{
+		{"grid", func(g *grid.Grid) dstarlite.Data { return g }},
+		{"wall follow", func(g *grid.Grid) dstarlite.Data { return grid.WallFollowCost(g, 0.9) }},
+		{"influence", func(g *grid.Grid) dstarlite.Data {
+			return grid.InfluenceCost(g, func(x, y int) float64 { return 10 }, 1)
+		}},
+	}
+	for _, diag := range []bool{false, true} {
+		opts := []grid.Option{grid.Weights(w)}
+		if diag {
+			opts = append(opts, grid.Diagonal())
+		}
+		g := grid.New(9, 9, opts...)
+		g.SetBlocked(grid.Coord{X: 4, Y: 4}, true)
+		for _, tst := range tests {
+			d := tst.d(g)
+			p := dstarlite.New(d, grid.Coord{X: 0, Y: 0}, goal)
+			p.Precompute()
+			for st, cost := range p.DistanceField() {
+				if dist := d.Dist(st, goal); dist > cost {
+					t.Errorf("%s (diagonal %v): Dist from %v is %v, above the cost %v", tst.name, diag, st, dist, cost)
+				}
+			}
+		}
+	}
+}
+
+func TestWeightsSaveLoad(t *testing.T) {
+	g := grid.New(3, 2, grid.Weights([][]float64{{1, 2, 3}, {4, 5, 6}}), grid.MinWeight(0.5))
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := grid.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, g) {
+		t.Errorf("loaded grid %+v, want %+v", loaded, g)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// NewWithHeuristic is like New, except that the planner computes the keys of
+// states (and the key modifier km, see UpdateStart) with the heuristic h
+// instead of data.Dist, while the rest of the planner (e.g. the propagation
+// limit, see SetPropagationLimit) keeps using data.Dist. The heuristic must be
+// admissible and consistent, like Dist; if it is nil data.Dist is used.
+//
+// The heuristic is kept by Clone and Reset.
+func NewWithHeuristic(data Data, start, goal State, h func(a, b State) float64, opts ...Option) *Planner {
+	withH := func(s *Planner) {
+		s.h = h
+	}
+	return New(data, start, goal, append([]Option{withH}, opts...)...)
+}
+
+// heuristic returns the heuristic distance from a to b, see NewWithHeuristic.
+func (s *PlannerOf[S]) heuristic(a, b S) float64 {
+	if s.h != nil {
+		return s.h(a, b)
+	}
+	return s.d.Dist(a, b)
+}
+
+// CompareHeuristics plans from start to goal through d once per heuristic
+// (each with its own planner, see NewWithHeuristic) and returns the number of
+// vertices each planner expanded, keyed by the name of the heuristic. This
+// allows picking the most efficient heuristic for a map.
+//
+// All heuristics must find paths of equal cost (within Tolerance); a heuristic
+// that finds a costlier path than the others (or none at all) overestimates
+// the distance, i.e. it is inadmissible. In that case the counts are returned
+// along with an error naming the offending heuristics.
+func CompareHeuristics(d Data, start, goal State, heuristics map[string]func(a, b State) float64) (map[string]int, error) {
+	names := make([]string, 0, len(heuristics))
+	for name := range heuristics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	counts := make(map[string]int, len(heuristics))
+	costs := make(map[string]float64, len(heuristics))
+	best := math.Inf(1)
+	for _, name := range names {
+		p := NewWithHeuristic(d, start, goal, heuristics[name])
+		cost := math.Inf(1)
+		if path := p.Plan(); path != nil {
+			cost = sumCost(d, path)
+		}
+		counts[name] = p.LastExpansions()
+		costs[name] = cost
+		best = math.Min(best, cost)
+	}
+
+	var bad []string
+	for _, name := range names {
+		if costs[name] > best && !float64Equals(costs[name], best) {
+			bad = append(bad, name)
+		}
+	}
+	if len(bad) > 0 {
+		return counts, fmt.Errorf("dstarlite: inadmissible heuristics %q found costlier paths", bad)
+	}
+	return counts, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"strings"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestCompareHeuristics(t *testing.T) {
+	tests := []struct {
+		name         string
+		m            string
+		inadmissible bool // Whether the inflated heuristic finds a costlier path.
+	}{
+		{"open", `
+S.........
+..........
+..........
+.........G
+`, false},
+		{"wall", `
+S.........
+..........
+.######...
+......#...
+......#..G
+......#...
+..........
+`, false},
+		{"spiral", `
+..........
+S.#####...
+..#.......
+..#.####..
+..#....#..
+..####.#..
+.......#.G
+`, false},
+		{"scattered", `
+S...#...
+##......
+........
+.....#..
+....#...
+.......G
+`, true},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		counts, err := dstarlite.CompareHeuristics(g, start, goal, map[string]func(a, b dstarlite.State) float64{
+			"zero":      func(a, b dstarlite.State) float64 { return 0 },
+			"manhattan": g.Dist,
+			"inflated":  func(a, b dstarlite.State) float64 { return 5 * g.Dist(a, b) },
+		})
+		if tst.inadmissible {
+			if err == nil || !strings.Contains(err.Error(), `"inflated"`) {
+				t.Errorf("%s: got error %v, want one naming the inflated heuristic", tst.name, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %v", tst.name, err)
+		}
+		if !(counts["zero"] >= counts["manhattan"] && counts["manhattan"] >= counts["inflated"]) {
+			t.Errorf("%s: got expansion counts %v, want zero >= manhattan >= inflated", tst.name, counts)
+		}
+	}
+}
+
+func TestNewWithHeuristic(t *testing.T) {
+	g, start, goal := parseGrid(`
+S.........
+..........
+.######...
+......#...
+......#..G
+......#...
+..........
+`)
+	ref := dstarlite.New(g, start, goal)
+	want := ref.Plan()
+	tests := []struct {
+		name string
+		h    func(a, b dstarlite.State) float64
+		more bool // Whether more vertices are expanded than with Dist.
+	}{
+		{"nil", nil, false},
+		{"dist", g.Dist, false},
+		{"zero", func(a, b dstarlite.State) float64 { return 0 }, true},
+	}
+	for _, tst := range tests {
+		p := dstarlite.NewWithHeuristic(g, start, goal, tst.h)
+		path := p.Plan()
+		if !costsEqual(p.PathCost(), ref.PathCost()) || len(path) != len(want) {
+			t.Errorf("%s: got path %v of cost %v, want %v of cost %v", tst.name, path, p.PathCost(), want, ref.PathCost())
+		}
+		got, base := p.LastExpansions(), ref.LastExpansions()
+		if tst.more && got <= base || !tst.more && got != base {
+			t.Errorf("%s: expanded %d vertices, Dist expanded %d", tst.name, got, base)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// CallStats counts the calls made to each method of a Data interface, see
+// InstrumentData.
+type CallStats struct {
+	CostCalls, DistCalls, SuccCalls, PredCalls int
+}
+
+// Reset sets all counters back to zero.
+func (c *CallStats) Reset() {
+	*c = CallStats{}
+}
+
+type instrumentedData struct {
+	d     Data
+	stats *CallStats
+}
+
+func (i instrumentedData) Succ(s State) []State {
+	i.stats.SuccCalls++
+	return i.d.Succ(s)
+}
+
+func (i instrumentedData) Pred(s State) []State {
+	i.stats.PredCalls++
+	return i.d.Pred(s)
+}
+
+func (i instrumentedData) Dist(a, b State) float64 {
+	i.stats.DistCalls++
+	return i.d.Dist(a, b)
+}
+
+func (i instrumentedData) Cost(a, b State) float64 {
+	i.stats.CostCalls++
+	return i.d.Cost(a, b)
+}
+
+// InstrumentData wraps the Data interface such that every call made to it
+// (e.g. by a planner) is counted in the returned CallStats. This is useful for
+// profiling expensive Cost functions, or catching accidental blowups in the
+// number of calls.
+//
+// The counters are not synchronized, so the returned Data must not be used
+// from multiple goroutines at once.
+func InstrumentData(d Data) (Data, *CallStats) {
+	stats := new(CallStats)
+	return instrumentedData{d, stats}, stats
+}
@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestInstrumentData(t *testing.T) {
+	g, start, goal := parseGrid(`
+S.#
+..G
+`)
+	d, stats := dstarlite.InstrumentData(g)
+	var p *dstarlite.Planner
+	plan := func() { p.Plan() }
+
+	// The counts are exact for this map; a change to them flags a change in
+	// how often the planner calls back into Data, such as a quadratic blowup.
+	tests := []struct {
+		name string
+		run  func()
+		want dstarlite.CallStats
+	}{
+		{"New", func() { p = dstarlite.New(d, start, goal) }, dstarlite.CallStats{DistCalls: 1}},
+		{"first Plan", plan, dstarlite.CallStats{CostCalls: 20, DistCalls: 11, SuccCalls: 3, PredCalls: 4}},
+		// The path is returned from the cache, without calling back into Data.
+		{"unchanged Plan", plan, dstarlite.CallStats{}},
+		// Only the walk along the path, and summing its cost (see PathCost),
+		// call back into Data.
+		{"reordered Plan", func() {
+			p.SetSuccessorOrder(nil)
+			p.Plan()
+		}, dstarlite.CallStats{CostCalls: 11, SuccCalls: 3}},
+	}
+	for _, tst := range tests {
+		stats.Reset()
+		tst.run()
+		if *stats != tst.want {
+			t.Errorf("%s: got %+v, want %+v", tst.name, *stats, tst.want)
+		}
+	}
+	stats.Reset()
+	if *stats != (dstarlite.CallStats{}) {
+		t.Errorf("Reset left %+v, want zero counts", *stats)
+	}
+}
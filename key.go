@@ -6,21 +6,44 @@ package dstarlite
 
 import (
 	"fmt"
+	"math"
 )
 
-// key is used to assign priority to states inside the DSL planner.
+// Key is used to assign priority to states inside the DSL planner. It is
+// exported for observation (see Planner.OnIteration) and for implementations
+// of PriorityQueue.
 //
 // Keys are compared in lexical order. That is, key a is considered less than
 // key b in the following case:
 //
 //  a1 < b1 || a1 == b1 && a2 < b2
 //
-type key struct {
+type Key struct {
 	A, B float64
 }
 
-func (a key) String() string {
-	return fmt.Sprintf("key(%v, %v)", a.A, a.B)
+// KeyTolerance, if positive, makes the planner consider keys whose components
+// differ by less than about KeyTolerance equal, which stops the floating point
+// error accumulated in km (over many calls to UpdateStart) from needlessly
+// reordering the priority queue. It is zero (exact comparison) by default,
+// and must not be modified while planning.
+//
+// To keep the ordering of keys consistent, both components are rounded to the
+// nearest multiple of KeyTolerance before being compared exactly, rather than
+// compared with a tolerance (which would not be transitive).
+var KeyTolerance float64
+
+// quantize returns the index of the multiple of KeyTolerance nearest to v, or
+// v itself if KeyTolerance is not positive.
+func quantize(v float64) float64 {
+	if KeyTolerance <= 0 || math.IsInf(v, 0) {
+		return v
+	}
+	return math.Floor(v/KeyTolerance + 0.5)
+}
+
+func (a Key) String() string {
+	return fmt.Sprintf("Key(%v, %v)", a.A, a.B)
 }
 
 // Compare tells if key A is less than key B.
@@ -31,7 +54,12 @@ func (a key) String() string {
 //
 // A == B returns 0
 //
-func (a key) compare(b key) int {
+func (a Key) compare(b Key) int {
+	if KeyTolerance > 0 {
+		a = Key{quantize(a.A), quantize(a.B)}
+		b = Key{quantize(b.A), quantize(b.B)}
+	}
+
 	if a.A < b.A {
 		return -1
 	} else if a.A > b.A {
@@ -46,3 +74,9 @@ func (a key) compare(b key) int {
 
 	return 0
 }
+
+// Less tells if key a is less than key b, in the order used by the planner
+// (which respects KeyTolerance).
+func (a Key) Less(b Key) bool {
+	return a.compare(b) == -1
+}
@@ -0,0 +1,46 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"errors"
+)
+
+// ErrExpansionLimit is returned by PlanE when the planner expanded the
+// maximum number of vertices set by SetMaxExpansions without finding the
+// path.
+var ErrExpansionLimit = errors.New("dstarlite: expansion limit reached")
+
+// ErrNodeLimit is returned by PlanE when the number of states tracked by the
+// planner exceeds the maximum set by SetMaxNodes.
+var ErrNodeLimit = errors.New("dstarlite: node limit reached")
+
+// SetMaxExpansions limits the number of vertices that a single call to Plan
+// may expand. Once the limit is hit, planning stops and PlanE returns
+// ErrExpansionLimit (Plan returns nil), which guards against pathological
+// replans, e.g. due to an inadmissible Dist heuristic. The count starts over
+// on every call to Plan, and a later call resumes the work where the previous
+// one stopped. A limit of zero (the default) disables it.
+func (s *PlannerOf[S]) SetMaxExpansions(n int) {
+	s.maxExpansions = n
+}
+
+// SetMaxNodes limits the number of distinct states that the planner tracks
+// (i.e. holds g or rhs values for), which bounds its memory use on huge or
+// untrusted maps. The limit is checked before each expansion, so it may be
+// exceeded by the predecessors of a single vertex. Once it is exceeded,
+// planning stops and PlanE returns ErrNodeLimit (Plan returns nil); since the
+// planner never forgets states, every later call to Plan does so as well until
+// the planner is Reset. A limit of zero (the default) disables it.
+func (s *PlannerOf[S]) SetMaxNodes(n int) {
+	s.maxNodes = n
+}
+
+// nodes returns the number of distinct states tracked by the planner. Every
+// state with a g value also has an rhs value (as g values are only ever set
+// to rhs values, or to +Inf once finite), so this is the size of the rhs map.
+func (s *PlannerOf[S]) nodes() int {
+	return len(s.rhs)
+}
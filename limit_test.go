@@ -0,0 +1,43 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestMaxNodes(t *testing.T) {
+	g := maze(21, 2)
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	want := dstarlite.New(g, start, goal).Plan()
+
+	p := dstarlite.New(g, start, goal)
+	p.SetMaxNodes(1000)
+	if path, err := p.PlanE(); err != nil || !reflect.DeepEqual(path, want) {
+		t.Errorf("with a loose limit got %v, %v; want %v", path, err, want)
+	}
+
+	p = dstarlite.New(g, start, goal)
+	p.SetMaxNodes(20)
+	for i := 0; i < 2; i++ {
+		if path, err := p.PlanE(); err != dstarlite.ErrNodeLimit || path != nil {
+			t.Errorf("call %d: got %v, %v; want nil, ErrNodeLimit", i, path, err)
+		}
+	}
+	if _, done := p.PlanWithin(time.Second); done {
+		t.Error("PlanWithin reported the node limit stop as finished")
+	}
+
+	// Lifting the limit resumes the work.
+	p.SetMaxNodes(0)
+	if path := p.Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("without the limit got %v, want %v", path, want)
+	}
+}
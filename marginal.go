@@ -0,0 +1,76 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// sumCost returns the sum of the edge costs along the path.
+func sumCost[S comparable](d DataOf[S], path []S) float64 {
+	var cost float64
+	for i := 1; i < len(path); i++ {
+		cost += d.Cost(path[i-1], path[i])
+	}
+	return cost
+}
+
+// PathCost returns the cost of an arbitrary path through d (e.g. one smoothed
+// or built by hand), summing the costs of its consecutive states like the
+// Planner.PathCost method does. It returns +Inf if any state is not a
+// successor (by State.Equals) of the one before it, since the cost of moving
+// between non-neighboring states is undefined, and also for an empty path. The
+// cost of a path consisting of a single state is zero.
+func PathCost(d Data, path []State) float64 {
+	if len(path) == 0 {
+		return math.Inf(1)
+	}
+	for i := 1; i < len(path); i++ {
+		if !containsState(d.Succ(path[i-1]), path[i]) {
+			return math.Inf(1)
+		}
+	}
+	return sumCost(d, path)
+}
+
+// MarginalCost returns how much blocking the given states (making them
+// impassable) would increase the cost of the path from the start to the goal
+// of the planner; its "blocking value". It returns zero if the block does not
+// affect the path cost (e.g. it blocks a redundant route), and +Inf if the
+// block disconnects the start from the goal. If there is no path to begin
+// with, it returns NaN.
+//
+// The block is applied to a copy of the planner (via FlagChanged on every edge
+// into and out of the blocked states) and replanned incrementally; the
+// planner itself is left untouched apart from a call to Plan.
+func MarginalCost(p *Planner, block []State) float64 {
+	path := p.Plan()
+	if path == nil {
+		return math.NaN()
+	}
+	oldCost := sumCost(p.d, path)
+
+	blocked := make(map[State]bool, len(block))
+	for _, st := range block {
+		blocked[st] = true
+	}
+	overlay := avoidData[State]{p.d, func(st State) bool { return blocked[st] }}
+
+	c := p.clone(overlay)
+	for _, st := range block {
+		for _, u := range c.pred(st) {
+			c.FlagChanged(u, st, p.d.Cost(u, st), math.Inf(1))
+		}
+		for _, v := range c.succ(st) {
+			c.FlagChanged(st, v, p.d.Cost(st, v), math.Inf(1))
+		}
+	}
+
+	newPath := c.Plan()
+	if newPath == nil {
+		return math.Inf(1)
+	}
+	return sumCost(overlay, newPath) - oldCost
+}
@@ -0,0 +1,92 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestMarginalCost(t *testing.T) {
+	corridor := `
+S.....G
+`
+	// Two equally long routes around the central wall.
+	redundant := `
+.......
+S.###.G
+.......
+`
+	// The lower route is the only short one.
+	detour := `
+.......
+.#####.
+S.....G
+`
+	tests := []struct {
+		name  string
+		m     string
+		block []grid.Coord
+		want  float64
+	}{
+		{"sole corridor", corridor, []grid.Coord{{X: 3, Y: 0}}, math.Inf(1)},
+		{"redundant route", redundant, []grid.Coord{{X: 3, Y: 0}}, 0},
+		{"other redundant route", redundant, []grid.Coord{{X: 3, Y: 2}}, 0},
+		{"both routes", redundant, []grid.Coord{{X: 3, Y: 0}, {X: 3, Y: 2}}, math.Inf(1)},
+		{"detour", detour, []grid.Coord{{X: 3, Y: 2}}, 4},
+		{"off the path", detour, []grid.Coord{{X: 3, Y: 0}}, 0},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		p := dstarlite.New(g, start, goal)
+		want := p.Plan()
+		block := make([]dstarlite.State, len(tst.block))
+		for i, c := range tst.block {
+			block[i] = c
+		}
+		if got := dstarlite.MarginalCost(p, block); got != tst.want {
+			t.Errorf("%s: got marginal cost %v, want %v", tst.name, got, tst.want)
+		}
+		if got := p.Plan(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: planner changed its path to %v, want %v", tst.name, got, want)
+		}
+	}
+
+	g, start, goal := parseGrid("S#G")
+	if got := dstarlite.MarginalCost(dstarlite.New(g, start, goal), nil); !math.IsNaN(got) {
+		t.Errorf("without a path got marginal cost %v, want NaN", got)
+	}
+}
+
+func TestPathCost(t *testing.T) {
+	g := graph{{"a", "b"}: 1, {"b", "c"}: 2, {"a", "c"}: 5, {"c", "d"}: math.Inf(1)}
+	tests := []struct {
+		name string
+		path []dstarlite.State
+		cost float64
+	}{
+		{"empty", nil, math.Inf(1)},
+		{"single state", nodes("a"), 0},
+		{"two edges", nodes("a", "b", "c"), 3},
+		{"direct edge", nodes("a", "c"), 5},
+		{"not a successor", nodes("a", "b", "a"), math.Inf(1)},
+		{"impassable edge", nodes("a", "c", "d"), math.Inf(1)},
+	}
+	for _, tst := range tests {
+		if cost := dstarlite.PathCost(g, tst.path); cost != tst.cost {
+			t.Errorf("%s: PathCost(%v) = %v, want %v", tst.name, tst.path, cost, tst.cost)
+		}
+	}
+
+	// The cost of a planned path is that reported by the planner.
+	p := dstarlite.New(g, node("a"), node("c"))
+	if path := p.Plan(); dstarlite.PathCost(g, path) != p.PathCost() {
+		t.Errorf("PathCost(%v) = %v, want %v", path, dstarlite.PathCost(g, path), p.PathCost())
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// maxHistory is the number of paths returned by Plan that are remembered for
+// DetectOscillation.
+const maxHistory = 32
+
+// record remembers a path returned by Plan.
+func (s *PlannerOf[S]) record(path []S) {
+	if len(s.history) == maxHistory {
+		copy(s.history, s.history[1:])
+		s.history = s.history[:maxHistory-1]
+	}
+	s.history = append(s.history, path)
+}
+
+// pathsEqual tells if the two paths consist of equal states.
+func (s *PlannerOf[S]) pathsEqual(a, b []S) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+	for i := range a {
+		if !s.eq(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameRoute tells if the later of two paths follows the same route as the
+// earlier one, that is if it equals the remainder of the earlier path from the
+// start of the later path on. Two nil paths (no path found) are the same
+// route as well.
+func (s *PlannerOf[S]) sameRoute(earlier, later []S) bool {
+	if earlier == nil || later == nil {
+		return earlier == nil && later == nil
+	}
+	for i, st := range earlier {
+		if s.eq(st, later[0]) {
+			return s.pathsEqual(earlier[i:], later)
+		}
+	}
+	return false
+}
+
+// DetectOscillation tells if the last window paths returned by Plan alternate
+// between a small set of routes, e.g. when competing soft costs make the path
+// flip between two routes on every replan. The caller may then add damping
+// (like a cost bonus for the current route).
+//
+// The path is considered to oscillate when some route is returned again after
+// a different route was returned in between (like A, B, A). As the agent may
+// move along the path between replans (see UpdateStart), a later path is
+// compared with the remainder of an earlier one from the start of the later
+// path on, so paths from a start that the earlier path does not pass through
+// never compare equal. At most the last 32 paths are remembered, larger
+// windows are clamped to that; a window of zero or less never oscillates.
+func (s *PlannerOf[S]) DetectOscillation(window int) bool {
+	if window <= 0 {
+		return false
+	}
+	if window > len(s.history) {
+		window = len(s.history)
+	}
+	paths := s.history[len(s.history)-window:]
+	for i := range paths {
+		left := false
+		for k := i + 1; k < len(paths); k++ {
+			same := s.sameRoute(paths[i], paths[k])
+			if !same {
+				left = true
+			} else if left {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestDetectOscillation(t *testing.T) {
+	top, bottom := grid.Coord{X: 1, Y: 0}, grid.Coord{X: 1, Y: 2}
+	tests := []struct {
+		name string
+		// tick changes the map (or moves the start) before the i-th plan.
+		tick func(g *grid.Grid, p *dstarlite.Planner, i int)
+		want bool
+	}{
+		{"alternating routes", func(g *grid.Grid, p *dstarlite.Planner, i int) {
+			setBlocked(g, top, i%2 == 0, p)
+			setBlocked(g, bottom, i%2 == 1, p)
+		}, true},
+		{"stable route", func(g *grid.Grid, p *dstarlite.Planner, i int) {
+			setBlocked(g, bottom, true, p)
+			setBlocked(g, grid.Coord{X: 3, Y: 3}, i%2 == 0, p)
+		}, false},
+		{"moving along the route", func(g *grid.Grid, p *dstarlite.Planner, i int) {
+			setBlocked(g, bottom, true, p)
+			if i > 0 && i < 5 {
+				p.UpdateStart(grid.Coord{X: i - 1, Y: 0})
+			}
+		}, false},
+	}
+	for _, tst := range tests {
+		// Two routes of equal cost lead around the wall.
+		g, start, goal := parseGrid(`
+S...
+.##.
+...G
+....
+`)
+		p := dstarlite.New(g, start, goal)
+		for i := 0; i < 6; i++ {
+			tst.tick(g, p, i)
+			p.Plan()
+		}
+		if got := p.DetectOscillation(6); got != tst.want {
+			t.Errorf("%s: DetectOscillation(6) = %v, want %v", tst.name, got, tst.want)
+		}
+		if p.DetectOscillation(2) || p.DetectOscillation(0) {
+			t.Errorf("%s: oscillation detected within fewer than three paths", tst.name)
+		}
+	}
+}
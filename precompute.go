@@ -0,0 +1,85 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// Precompute expands every vertex in the priority queue, such that the g
+// values of all states from which the goal can be reached are known (not just
+// those needed for the path from the start). Subsequent calls to Plan, even
+// after UpdateStart, can then walk the path without expanding any vertices.
+func (s *PlannerOf[S]) Precompute() {
+	for !s.u.IsEmpty() {
+		s.expand()
+	}
+	s.dirty = false
+	s.walked = false
+}
+
+// PrecomputeBudget is like Precompute, except that it performs at most
+// maxExpansions expansions. It returns true once the field is fully computed.
+//
+// It is intended to be called once per frame (e.g. during a loading screen)
+// to spread the cost of Precompute over time. The planner is left in a
+// consistent state between calls, so the work of each call is kept and never
+// redone; Plan may also be called in between, it simply finishes the part of
+// the work that it needs itself.
+func (s *PlannerOf[S]) PrecomputeBudget(maxExpansions int) bool {
+	for i := 0; i < maxExpansions && !s.u.IsEmpty(); i++ {
+		s.expand()
+	}
+	if s.u.IsEmpty() {
+		s.dirty = false
+	s.walked = false
+		return true
+	}
+	return false
+}
+
+// DistanceField returns a snapshot of the cost-to-goal field computed so far:
+// the g value of every state for which it is finite. This may drive e.g. the
+// flow-field steering of many agents sharing a goal.
+//
+// Only states expanded while planning appear, which for Plan is roughly the
+// region needed to find the path from the start; call Precompute first for
+// the field of every state from which a goal can be reached. The returned map
+// is a copy, which can be read while the planner keeps replanning.
+func (s *PlannerOf[S]) DistanceField() map[S]float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	field := make(map[S]float64, len(s.g))
+	for st, g := range s.g {
+		if !math.IsInf(g, 0) {
+			field[st] = g
+		}
+	}
+	return field
+}
+
+// Reachable returns the states from which a goal is known to be reachable
+// given the current costs, in no particular order: every goal, and every other
+// state with a finite g value.
+//
+// Like DistanceField, only states expanded while planning are known, so states
+// that are reachable but were not (yet) expanded are absent; after Precompute
+// the result is the whole region from which a goal can be reached. States
+// outside the region needed by the last call to Plan may also still reflect
+// costs as they were before the last changes, which Precompute resolves too.
+func (s *PlannerOf[S]) Reachable() []S {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var states []S
+	for st, g := range s.g {
+		if !math.IsInf(g, 0) && !s.isGoal(st) {
+			states = append(states, st)
+		}
+	}
+	for g := range s.goals {
+		states = append(states, g)
+	}
+	return states
+}
@@ -0,0 +1,113 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestPrecomputeBudget(t *testing.T) {
+	g := maze(21, 3)
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	starts := []dstarlite.State{start, grid.Coord{X: 20, Y: 0}, grid.Coord{X: 0, Y: 20}, grid.Coord{X: 10, Y: 10}}
+
+	whole := dstarlite.New(g, start, goal)
+	whole.Precompute()
+	want := whole.LastExpansions()
+	field := whole.DistanceField()
+	var paths [][]dstarlite.State
+	for _, s := range starts {
+		whole.UpdateStart(s)
+		paths = append(paths, whole.Plan())
+	}
+
+	for _, budget := range []int{1, 7, 100, want, want + 1} {
+		p := dstarlite.New(g, start, goal)
+		calls := 0
+		for !p.PrecomputeBudget(budget) {
+			calls++
+			if calls > want {
+				t.Fatalf("budget %d: not done after %d calls", budget, calls)
+			}
+		}
+		if n := p.LastExpansions(); n != want {
+			t.Errorf("budget %d: %d expansions, want %d as by Precompute", budget, n, want)
+		}
+		if got := p.DistanceField(); !reflect.DeepEqual(got, field) {
+			t.Errorf("budget %d: field differs from the one computed by Precompute", budget)
+		}
+		if !p.PrecomputeBudget(budget) {
+			t.Errorf("budget %d: PrecomputeBudget returned false after the field was computed", budget)
+		}
+		for i, s := range starts {
+			p.UpdateStart(s)
+			if path := p.Plan(); !reflect.DeepEqual(path, paths[i]) {
+				t.Errorf("budget %d: got path %v from %v, want %v", budget, path, s, paths[i])
+			}
+			if n := p.LastExpansions(); n != 0 {
+				t.Errorf("budget %d: Plan from %v expanded %d vertices after precomputing", budget, s, n)
+			}
+		}
+	}
+}
+
+func TestDistanceField(t *testing.T) {
+	g, start, goal := parseGrid(`
+S.G
+.#.
+...
+`)
+	p := dstarlite.New(g, start, goal)
+	p.Precompute()
+	want := map[dstarlite.State]float64{
+		grid.Coord{X: 2, Y: 0}: 0,
+		grid.Coord{X: 1, Y: 0}: 1, grid.Coord{X: 2, Y: 1}: 1,
+		grid.Coord{X: 0, Y: 0}: 2, grid.Coord{X: 2, Y: 2}: 2,
+		grid.Coord{X: 0, Y: 1}: 3, grid.Coord{X: 1, Y: 2}: 3,
+		grid.Coord{X: 0, Y: 2}: 4,
+	}
+	field := p.DistanceField()
+	if !reflect.DeepEqual(field, want) {
+		t.Fatalf("got field %v, want %v", field, want)
+	}
+
+	// The field is a copy, unaffected by replanning.
+	setBlocked(g, grid.Coord{X: 1, Y: 0}, true, p)
+	p.Precompute()
+	if !reflect.DeepEqual(field, want) {
+		t.Errorf("field changed to %v by replanning", field)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	// The room in the bottom right corner is walled off from the goal.
+	g, start, goal := parseGrid(`
+S..#.
+.G.##
+...#.
+`)
+	p := dstarlite.New(g, start, goal)
+	p.Precompute()
+	got := make(map[dstarlite.State]bool)
+	for _, st := range p.Reachable() {
+		if got[st] {
+			t.Errorf("state %v listed twice", st)
+		}
+		got[st] = true
+	}
+	want := make(map[dstarlite.State]bool)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			want[grid.Coord{X: x, Y: y}] = true
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got reachable states %v, want %v", got, want)
+	}
+}
@@ -9,9 +9,71 @@ import (
 	"math"
 )
 
-type pqItem struct {
-	s State
-	k key
+// PriorityQueueOf is the priority queue U of the D* Lite algorithm, which
+// holds the locally inconsistent vertices ordered by their keys (see
+// Key.Less). Each state is held at most once. The planner uses a binary heap
+// by default; other implementations may be used through the Queue option.
+type PriorityQueueOf[S comparable] interface {
+	// Insert inserts state s, which is not in the queue, with priority k.
+	Insert(s S, k Key)
+
+	// Update changes the priority of state s, which is in the queue, to k.
+	Update(s S, k Key)
+
+	// Remove removes state s, which is in the queue.
+	Remove(s S)
+
+	// Top returns a state with the smallest priority of all states in the
+	// queue, which is not empty. States of equal priority should be returned
+	// in the order they were inserted (or last updated), to keep planning
+	// deterministic.
+	Top() S
+
+	// TopKey returns the smallest priority of all states in the queue, or
+	// Key{+Inf, +Inf} if the queue is empty.
+	TopKey() Key
+
+	// Contains tells if state s is in the queue.
+	Contains(s S) bool
+
+	// IsEmpty tells if the queue is empty.
+	IsEmpty() bool
+
+	// Pop removes and returns the state that Top returns.
+	Pop() S
+
+	// Each calls f with every state in the queue and its priority, in any
+	// order. The queue is not modified during the calls.
+	Each(f func(s S, k Key))
+}
+
+// PriorityQueue is the PriorityQueueOf for State interfaces, as used by
+// Planner.
+type PriorityQueue = PriorityQueueOf[State]
+
+// Queue makes the planner use the priority queues returned by newQueue (one
+// per planner, including each copy made by Clone) instead of the default
+// binary heap. Each returned queue must be empty.
+func Queue[S comparable](newQueue func() PriorityQueueOf[S]) OptionOf[S] {
+	return func(s *PlannerOf[S]) {
+		s.queue = newQueue
+	}
+}
+
+// newQueue returns a new, empty, priority queue for the planner.
+func (s *PlannerOf[S]) newQueue() PriorityQueueOf[S] {
+	if s.queue != nil {
+		return s.queue()
+	}
+	return newPriorityQueue[S]()
+}
+
+type pqItem[S comparable] struct {
+	s S
+	k Key
+
+	// Insertion sequence number, which breaks ties between equal keys.
+	seq uint64
 
 	// The index is needed by update and is maintained by the heap.Interface methods.
 	//index int // The index of the item in the heap.
@@ -19,34 +81,50 @@ type pqItem struct {
 	// Note: Kept by lookups map below instead
 }
 
-type priorityQueue struct {
+// less tells if item a is popped before item b.
+func (a pqItem[S]) less(b pqItem[S]) bool {
+	// We want Pop to give us the lowest priority so we use less than here.
+	//
+	// Remember from compare() docs that:
+	//
+	// A < B returns -1
+	//
+	if c := a.k.compare(b.k); c != 0 {
+		return c == -1
+	}
+	return a.seq < b.seq
+}
+
+// priorityQueue pops vertices in order of their keys. Vertices of equal keys
+// are popped in the order in which they were inserted (or last updated), such
+// that planning is deterministic given a deterministic Data interface.
+type priorityQueue[S comparable] struct {
 	// State:index
-	lookups map[State]int
-	items   []pqItem
+	lookups map[S]int
+	items   []pqItem[S]
+
+	// Sequence number of the next inserted item.
+	seq uint64
 }
 
 //
 // heap.Interface methods
 //
+// These are implemented by pqHeap, a view of the queue, such that the Pop
+// method of heap.Interface does not clash with that of PriorityQueue.
+//
 
-func (q *priorityQueue) Len() int {
+type pqHeap[S comparable] priorityQueue[S]
+
+func (q *pqHeap[S]) Len() int {
 	return len(q.items)
 }
 
-func (q *priorityQueue) Less(i, j int) bool {
-	a := q.items[i]
-	b := q.items[j]
-
-	// We want Pop to give us the lowest priority so we use less than here.
-	//
-	// Remember from compare() docs that:
-	//
-	// A < B returns -1
-	//
-	return a.k.compare(b.k) == -1
+func (q *pqHeap[S]) Less(i, j int) bool {
+	return q.items[i].less(q.items[j])
 }
 
-func (q *priorityQueue) Swap(i, j int) {
+func (q *pqHeap[S]) Swap(i, j int) {
 	q.items[i], q.items[j] = q.items[j], q.items[i]
 
 	// Update item indices in lookups map
@@ -54,14 +132,14 @@ func (q *priorityQueue) Swap(i, j int) {
 	q.lookups[q.items[j].s] = j
 }
 
-func (q *priorityQueue) Push(x interface{}) {
+func (q *pqHeap[S]) Push(x interface{}) {
 	n := len(q.items)
-	item := x.(pqItem)
+	item := x.(pqItem[S])
 	q.lookups[item.s] = n
 	q.items = append(q.items, item)
 }
 
-func (q *priorityQueue) Pop() interface{} {
+func (q *pqHeap[S]) Pop() interface{} {
 	old := q.items
 	n := len(old)
 	item := old[n-1]
@@ -74,66 +152,108 @@ func (q *priorityQueue) Pop() interface{} {
 // Methods as described by the paper
 //
 
-func (q *priorityQueue) contains(s State) bool {
+func (q *priorityQueue[S]) Contains(s S) bool {
 	_, ok := q.lookups[s]
 	return ok
 }
 
-func (q *priorityQueue) isEmpty() bool {
+func (q *priorityQueue[S]) IsEmpty() bool {
 	return len(q.lookups) == 0
 }
 
 // U.Top(): returns a vertex with the smallest priority of all vertices in
 // priority queue U.
-func (q *priorityQueue) top() State {
+func (q *priorityQueue[S]) Top() S {
 	return q.items[0].s
 }
 
 // U.TopKey() returns the smallest priority of all vertices in priority queue
 // U.
 //
-// If U is empty, then U.TopKey() returns key{Inf, Inf}
-func (q *priorityQueue) topKey() key {
+// If U is empty, then U.TopKey() returns Key{Inf, Inf}
+func (q *priorityQueue[S]) TopKey() Key {
 	if len(q.items) == 0 {
-		return key{math.Inf(1), math.Inf(1)}
+		return Key{math.Inf(1), math.Inf(1)}
 	}
 	return q.items[0].k
 }
 
 // U.Pop() deletes the vertex with the smallest priority in priority queue U
 // and returns the vertex.
-func (q *priorityQueue) pop() State {
-	return heap.Pop(q).(pqItem).s
+func (q *priorityQueue[S]) Pop() S {
+	s := q.items[0].s
+	q.Remove(s)
+	return s
 }
 
+// The queue operations below maintain the heap order through heap.Fix rather
+// than heap.Push and heap.Pop, since passing items through their interface{}
+// arguments allocates.
+
 // U.Insert(s, k) inserts vertex s into priority queue U with priority k.
-func (q *priorityQueue) insert(s State, k key) {
-	heap.Push(q, pqItem{s, k})
+func (q *priorityQueue[S]) Insert(s S, k Key) {
+	n := len(q.items)
+	q.lookups[s] = n
+	q.items = append(q.items, q.item(s, k))
+	heap.Fix((*pqHeap[S])(q), n)
+}
+
+// item returns a new item for vertex s with priority k.
+func (q *priorityQueue[S]) item(s S, k Key) pqItem[S] {
+	q.seq++
+	return pqItem[S]{s, k, q.seq}
 }
 
 // U.Update(s, k) changes the priority of vertex s in priority queue U to k.
 //
 // It does nothing if the current priority of vertex s already equals k.
-func (q *priorityQueue) update(s State, k key) {
+func (q *priorityQueue[S]) Update(s S, k Key) {
 	index := q.lookups[s]
 
 	// Check if current priority is already 'k' (a.compare(b) == 0 means perfectly equal)
 	if q.items[index].k.compare(k) != 0 {
-		heap.Remove(q, index)
-		heap.Push(q, pqItem{s, k})
+		q.items[index] = q.item(s, k)
+		heap.Fix((*pqHeap[S])(q), index)
 	}
 }
 
 // U.Remove(s) removes vertex s from priority queue U.
-func (q *priorityQueue) remove(s State) {
+func (q *priorityQueue[S]) Remove(s S) {
 	index := q.lookups[s]
+	n := len(q.items) - 1
+	if index != n {
+		(*pqHeap[S])(q).Swap(index, n)
+	}
 	delete(q.lookups, s)
-	heap.Remove(q, index)
+	q.items[n] = pqItem[S]{}
+	q.items = q.items[:n]
+	if index != n {
+		heap.Fix((*pqHeap[S])(q), index)
+	}
+}
+
+// Each calls f with every state in the queue and its priority, in heap order.
+func (q *priorityQueue[S]) Each(f func(s S, k Key)) {
+	for _, item := range q.items {
+		f(item.s, item.k)
+	}
+}
+
+// reset removes all items from the queue, keeping the allocated memory.
+func (q *priorityQueue[S]) reset() {
+	for s := range q.lookups {
+		delete(q.lookups, s)
+	}
+	for i := range q.items {
+		q.items[i] = pqItem[S]{}
+	}
+	q.items = q.items[:0]
+	q.seq = 0
 }
 
-func newPriorityQueue() *priorityQueue {
-	q := new(priorityQueue)
-	q.lookups = make(map[State]int)
-	q.items = make([]pqItem, 0)
+func newPriorityQueue[S comparable]() *priorityQueue[S] {
+	q := new(priorityQueue[S])
+	q.lookups = make(map[S]int)
+	q.items = make([]pqItem[S], 0)
 	return q
 }
@@ -0,0 +1,301 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"container/heap"
+	"math"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// fan is a Data interface in which each of its leaves lies on a distinct path
+// of two edges, each of cost one, from the state "start" to the state "hub".
+// Pred returns the leaves in order.
+type fan []node
+
+func (d fan) Succ(s dstarlite.State) []dstarlite.State {
+	switch s {
+	case node("start"):
+		return nodes(d...)
+	case node("hub"):
+		return nil
+	}
+	return nodes("hub")
+}
+
+func (d fan) Pred(s dstarlite.State) []dstarlite.State {
+	switch s {
+	case node("start"):
+		return nil
+	case node("hub"):
+		return nodes(d...)
+	}
+	return nodes("start")
+}
+
+func (d fan) Dist(a, b dstarlite.State) float64 { return 0 }
+
+func (d fan) Cost(a, b dstarlite.State) float64 { return 1 }
+
+func TestQueueTiesInInsertionOrder(t *testing.T) {
+	for _, leaves := range []fan{
+		{"a", "b", "c", "d", "e"},
+		{"e", "d", "c", "b", "a"},
+	} {
+		// All leaves share the same key, and are inserted in the order given
+		// by Pred.
+		p := dstarlite.New(leaves, node("start"), node("hub"))
+		var order fan
+		p.OnExpand(func(st dstarlite.State, k dstarlite.Key, overconsistent bool) {
+			if st != node("start") && st != node("hub") {
+				order = append(order, st.(node))
+			}
+		})
+		p.Plan()
+		if !reflect.DeepEqual(order, leaves) {
+			t.Errorf("leaves %v expanded in order %v", leaves, order)
+		}
+	}
+}
+
+// heapItem is an item of heapQueue.
+type heapItem struct {
+	s   dstarlite.State
+	k   dstarlite.Key
+	seq int
+}
+
+// heapItems implements heap.Interface for heapQueue.
+type heapItems struct {
+	items   []*heapItem
+	lookups map[dstarlite.State]int
+}
+
+func (h *heapItems) Len() int { return len(h.items) }
+
+func (h *heapItems) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if a.k != b.k {
+		return a.k.Less(b.k)
+	}
+	return a.seq < b.seq
+}
+
+func (h *heapItems) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.lookups[h.items[i].s] = i
+	h.lookups[h.items[j].s] = j
+}
+
+func (h *heapItems) Push(x interface{}) {
+	item := x.(*heapItem)
+	h.lookups[item.s] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *heapItems) Pop() interface{} {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.lookups, item.s)
+	return item
+}
+
+// heapQueue is a straightforward binary heap priority queue, which passes its
+// items through container/heap (boxing them in interface{} values) and
+// updates keys by removing and reinserting items, or if fix is set by
+// sifting them in place. It pops items in the same order as the default
+// queue, so it serves as a reference for it.
+type heapQueue struct {
+	h   heapItems
+	seq int
+	fix bool
+}
+
+func newHeapQueue() dstarlite.PriorityQueue {
+	return &heapQueue{h: heapItems{lookups: make(map[dstarlite.State]int)}}
+}
+
+func newFixHeapQueue() dstarlite.PriorityQueue {
+	q := newHeapQueue().(*heapQueue)
+	q.fix = true
+	return q
+}
+
+func (q *heapQueue) Insert(s dstarlite.State, k dstarlite.Key) {
+	q.seq++
+	heap.Push(&q.h, &heapItem{s, k, q.seq})
+}
+
+func (q *heapQueue) Update(s dstarlite.State, k dstarlite.Key) {
+	i := q.h.lookups[s]
+	switch {
+	case q.h.items[i].k == k:
+	case q.fix:
+		q.seq++
+		q.h.items[i].k, q.h.items[i].seq = k, q.seq
+		heap.Fix(&q.h, i)
+	default:
+		q.Remove(s)
+		q.Insert(s, k)
+	}
+}
+
+func (q *heapQueue) Remove(s dstarlite.State) {
+	heap.Remove(&q.h, q.h.lookups[s])
+}
+
+func (q *heapQueue) Top() dstarlite.State { return q.h.items[0].s }
+
+func (q *heapQueue) TopKey() dstarlite.Key {
+	if len(q.h.items) == 0 {
+		return dstarlite.Key{A: math.Inf(1), B: math.Inf(1)}
+	}
+	return q.h.items[0].k
+}
+
+func (q *heapQueue) Contains(s dstarlite.State) bool {
+	_, ok := q.h.lookups[s]
+	return ok
+}
+
+func (q *heapQueue) IsEmpty() bool { return len(q.h.items) == 0 }
+
+func (q *heapQueue) Pop() dstarlite.State {
+	return heap.Pop(&q.h).(*heapItem).s
+}
+
+func (q *heapQueue) Each(f func(s dstarlite.State, k dstarlite.Key)) {
+	for _, item := range q.h.items {
+		f(item.s, item.k)
+	}
+}
+
+// replanMaze plans across an open maze, then replans after blocking and
+// unblocking cells on its diagonal in turn, calling f after every plan.
+func replanMaze(p *dstarlite.Planner, g *grid.Grid, f func(path []dstarlite.State)) {
+	g.SetPlanner(p)
+	f(p.Plan())
+	for i := 0; i < 10; i++ {
+		c := grid.Coord{X: 4 + 3*i, Y: 4 + 3*i}
+		g.SetBlocked(c, true)
+		f(p.Plan())
+		g.SetBlocked(c, false)
+		f(p.Plan())
+	}
+}
+
+func TestQueueMatchesReference(t *testing.T) {
+	type result struct {
+		path       []dstarlite.State
+		expansions int
+	}
+	results := func(seed int64, opts ...dstarlite.Option) []result {
+		var r []result
+		g := openMaze(41, seed)
+		p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 40, Y: 40}, opts...)
+		replanMaze(p, g, func(path []dstarlite.State) {
+			r = append(r, result{path, p.LastExpansions()})
+		})
+		return r
+	}
+	for _, seed := range []int64{1, 2, 3} {
+		got := results(seed)
+		if want := results(seed, dstarlite.Queue(newHeapQueue)); !reflect.DeepEqual(got, want) {
+			t.Errorf("seed %d: default queue planned differently than the reference queue", seed)
+		}
+		if want := results(seed, dstarlite.Queue(newFixHeapQueue)); !reflect.DeepEqual(got, want) {
+			t.Errorf("seed %d: default queue planned differently than the sifting reference queue", seed)
+		}
+	}
+}
+
+func TestQueueClone(t *testing.T) {
+	g := openMaze(41, 2)
+	p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 40, Y: 40}, dstarlite.Queue(newHeapQueue))
+	path := p.Plan()
+	queue := p.QueueSnapshot()
+	c := p.Clone()
+	if got := c.QueueSnapshot(); !reflect.DeepEqual(got, queue) {
+		t.Errorf("clone has queue %v, want %v", got, queue)
+	}
+
+	// Blocking the path of the clone must leave the queue of the original
+	// alone, and the clone must replan as a fresh planner would.
+	g.SetPlanner(c)
+	g.SetBlocked(path[len(path)/2].(grid.Coord), true)
+	got := c.Plan()
+	if want := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 40, Y: 40}).Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("clone planned %v, want %v", got, want)
+	}
+	if got := p.QueueSnapshot(); !reflect.DeepEqual(got, queue) {
+		t.Errorf("original queue changed")
+	}
+}
+
+// wallData blocks the states of a set on top of a Data interface.
+type wallData struct {
+	dstarlite.Data
+	walls map[dstarlite.State]bool
+}
+
+func (w wallData) Cost(a, b dstarlite.State) float64 {
+	if w.walls[a] || w.walls[b] {
+		return math.Inf(1)
+	}
+	return w.Data.Cost(a, b)
+}
+
+// setWall sets whether the state st is a wall, and notifies the planner p of
+// the changed edges.
+func (w wallData) setWall(p *dstarlite.Planner, st dstarlite.State, wall bool) {
+	succ := w.Succ(st)
+	old := make([]float64, 0, 2*len(succ))
+	for _, n := range succ {
+		old = append(old, w.Cost(st, n), w.Cost(n, st))
+	}
+	w.walls[st] = wall
+	for i, n := range succ {
+		p.FlagChanged(st, n, old[2*i], w.Cost(st, n))
+		p.FlagChanged(n, st, old[2*i+1], w.Cost(n, st))
+	}
+}
+
+// benchmarkQueue plans across a frozen open maze with the given options, then
+// replans as walls appear and disappear on its diagonal. Apart from notifying
+// the planner the maze makes no allocations of its own, such that the
+// allocations reported are mostly those of the planner.
+func benchmarkQueue(b *testing.B, opts ...dstarlite.Option) {
+	g := openMaze(63, 1)
+	w := wallData{dstarlite.FreezeData(g, gridCells(g)), make(map[dstarlite.State]bool)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := dstarlite.New(w, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 62, Y: 62}, opts...)
+		p.Plan()
+		for j := 0; j < 20; j++ {
+			c := grid.Coord{X: 4 + 3*j, Y: 4 + 3*j}
+			w.setWall(p, c, true)
+			p.Plan()
+			w.setWall(p, c, false)
+			p.Plan()
+		}
+	}
+}
+
+func BenchmarkQueue(b *testing.B) {
+	benchmarkQueue(b)
+}
+
+func BenchmarkReferenceQueue(b *testing.B) {
+	benchmarkQueue(b, dstarlite.Queue(newHeapQueue))
+}
+
+func BenchmarkReferenceQueueFix(b *testing.B) {
+	benchmarkQueue(b, dstarlite.Queue(newFixHeapQueue))
+}
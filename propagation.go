@@ -0,0 +1,37 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// SetPropagationLimit limits how far the effects of edge cost changes spread
+// when replanning: rhs updates are only propagated to states within the given
+// Dist radius of a state passed to FlagChanged since the last call to Plan.
+// This bounds the cost of a replan on huge maps, at the expense of
+// optimality. A radius of zero (the default) disables the limit.
+//
+// The g and rhs values of states outside the radius are left stale, so a
+// change far away from the agent may go unnoticed until it is replanned from
+// scratch, and paths through the stale region may be suboptimal or lead
+// through edges that are no longer passable. Planning from scratch (the first
+// call to Plan, or after a reinit) is never limited.
+func (s *PlannerOf[S]) SetPropagationLimit(radius float64) {
+	s.propLimit = radius
+	if radius <= 0 {
+		s.changed = nil
+	}
+}
+
+// beyondLimit tells if st lies outside the propagation radius of every
+// changed state, see SetPropagationLimit.
+func (s *PlannerOf[S]) beyondLimit(st S) bool {
+	if s.propLimit <= 0 || len(s.changed) == 0 {
+		return false
+	}
+	for _, c := range s.changed {
+		if s.d.Dist(c, st) <= s.propLimit {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestPropagationLimit(t *testing.T) {
+	tests := []struct {
+		limit   float64
+		ignored bool // Whether the change is too far away to reach the start.
+	}{
+		{0, false},
+		{30, false},
+		{5, true},
+		{2, true},
+	}
+	unlimited := -1
+	for _, tst := range tests {
+		// A wall with a gap at the bottom; the agent starts in the top left
+		// corner, and the goal is in the top right corner behind the wall.
+		g := grid.New(40, 21)
+		for y := 0; y < 20; y++ {
+			g.SetBlocked(grid.Coord{X: 30, Y: y}, true)
+		}
+		start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 39, Y: 0}
+		p := dstarlite.New(g, start, goal)
+		p.SetPropagationLimit(tst.limit)
+		g.SetPlanner(p)
+		before := pathCost(g, p.Plan())
+
+		// Open a shortcut through the wall, 30 cells away from the agent.
+		g.SetBlocked(grid.Coord{X: 30, Y: 0}, false)
+		var buf bytes.Buffer
+		p.SetTrace(&buf)
+		path, err := p.PlanE()
+		p.SetTrace(nil)
+		if err != nil {
+			t.Fatalf("limit %v: %v", tst.limit, err)
+		}
+		for i := 1; i < len(path); i++ {
+			if c := g.Cost(path[i-1], path[i]); math.IsInf(c, 1) {
+				t.Fatalf("limit %v: path %v moves from %v to %v through a wall", tst.limit, path, path[i-1], path[i])
+			}
+		}
+		if path[0] != start || path[len(path)-1] != goal {
+			t.Errorf("limit %v: path %v does not lead from %v to %v", tst.limit, path, start, goal)
+		}
+
+		// Whether the cost-to-goal of the start was updated by the replan.
+		updated := false
+		for _, l := range parseTrace(t, buf.String()) {
+			if l.op == "rhs" && l.state == fmt.Sprint(start) {
+				updated = true
+			}
+		}
+		n, cost := p.LastExpansions(), pathCost(g, path)
+		switch {
+		case tst.limit == 0:
+			unlimited = n
+			if !updated || cost >= before {
+				t.Errorf("limit 0: path costs %v after opening the shortcut, want less than %v", cost, before)
+			}
+		case tst.ignored:
+			if updated {
+				t.Errorf("limit %v: the cost-to-goal of the start was updated, want the change beyond the limit ignored", tst.limit)
+			}
+			if n >= unlimited {
+				t.Errorf("limit %v: replan expanded %d vertices, want fewer than the %d without limit", tst.limit, n, unlimited)
+			}
+		default:
+			if n != unlimited {
+				t.Errorf("limit %v: replan expanded %d vertices, want %d as without limit", tst.limit, n, unlimited)
+			}
+		}
+	}
+}
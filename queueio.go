@@ -0,0 +1,123 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+// StateCodecOf encodes and decodes states to and from a binary stream, since
+// the concrete state types are unknown to this package.
+type StateCodecOf[S comparable] interface {
+	// EncodeState writes the state to w.
+	EncodeState(w io.Writer, s S) error
+
+	// DecodeState reads a single state, as written by EncodeState, from r.
+	DecodeState(r io.Reader) (S, error)
+}
+
+// StateCodec is the StateCodecOf for State interfaces, as used by Planner.
+type StateCodec = StateCodecOf[State]
+
+// QueueItemOf is a single state in the priority queue, and its key.
+type QueueItemOf[S comparable] struct {
+	State S
+	Key   Key
+}
+
+// QueueItem is the QueueItemOf for State interfaces, as used by Planner.
+type QueueItem = QueueItemOf[State]
+
+// QueueSnapshotOf is a copy of the items of the priority queue (the open
+// list) of a planner, in the order in which the queue pops them. Inserting the
+// items into an empty queue in this order hence reproduces the pop order,
+// including that of items with equal keys.
+type QueueSnapshotOf[S comparable] []QueueItemOf[S]
+
+// QueueSnapshot is the QueueSnapshotOf for State interfaces, as used by
+// Planner.
+type QueueSnapshot = QueueSnapshotOf[State]
+
+// QueueSnapshot returns a copy of the items in the priority queue.
+//
+// For queues other than the default one (see the Queue option), items of
+// equal keys are in the order given by PriorityQueue.Each.
+func (s *PlannerOf[S]) QueueSnapshot() QueueSnapshotOf[S] {
+	if q, ok := s.u.(*priorityQueue[S]); ok {
+		items := append([]pqItem[S](nil), q.items...)
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].less(items[j])
+		})
+		snap := make(QueueSnapshotOf[S], len(items))
+		for i, item := range items {
+			snap[i] = QueueItemOf[S]{item.s, item.k}
+		}
+		return snap
+	}
+	var snap QueueSnapshotOf[S]
+	s.u.Each(func(st S, k Key) {
+		snap = append(snap, QueueItemOf[S]{st, k})
+	})
+	sort.SliceStable(snap, func(i, j int) bool {
+		return snap[i].Key.Less(snap[j].Key)
+	})
+	return snap
+}
+
+// ExportQueue writes the items of the priority queue to w, using the codec to
+// encode each state. Combined with the g and rhs values this allows handing a
+// search off to another process mid-way.
+//
+// The format is a little-endian uint32 item count followed by each item, in
+// pop order (see QueueSnapshot): the encoded state and the two float64
+// components of its key.
+func (s *PlannerOf[S]) ExportQueue(codec StateCodecOf[S], w io.Writer) error {
+	snap := s.QueueSnapshot()
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(snap))); err != nil {
+		return err
+	}
+	for _, item := range snap {
+		if err := codec.EncodeState(w, item.State); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, [2]float64{item.Key.A, item.Key.B}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportQueue replaces the priority queue of the planner with the items read
+// from r, as written by ExportQueue. Since the items are written in pop order
+// (see QueueSnapshot), they are popped in the same order as they would be
+// from the exporting planner, even among equal keys. On error the queue is
+// left unchanged.
+func (s *PlannerOf[S]) ImportQueue(codec StateCodecOf[S], r io.Reader) error {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return err
+	}
+	q := s.newQueue()
+	for i := uint32(0); i < n; i++ {
+		st, err := codec.DecodeState(r)
+		if err != nil {
+			return err
+		}
+		var k [2]float64
+		if err := binary.Read(r, binary.LittleEndian, &k); err != nil {
+			return err
+		}
+		if q.Contains(st) {
+			return errors.New("dstarlite: duplicate state in imported queue")
+		}
+		q.Insert(st, Key{k[0], k[1]})
+	}
+	s.u = q
+	s.dirty = true
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// coordCodec encodes grid cells as two little-endian int32 coordinates.
+type coordCodec struct{}
+
+func (coordCodec) EncodeState(w io.Writer, s dstarlite.State) error {
+	c := s.(grid.Coord)
+	return binary.Write(w, binary.LittleEndian, [2]int32{int32(c.X), int32(c.Y)})
+}
+
+func (coordCodec) DecodeState(r io.Reader) (dstarlite.State, error) {
+	var c [2]int32
+	if err := binary.Read(r, binary.LittleEndian, &c); err != nil {
+		return nil, err
+	}
+	return grid.Coord{X: int(c[0]), Y: int(c[1])}, nil
+}
+
+// precomputeTrace precomputes the field of p and returns its trace, which
+// lists the states in the order in which they were popped.
+func precomputeTrace(p *dstarlite.Planner) string {
+	var buf bytes.Buffer
+	p.SetTrace(&buf)
+	p.Precompute()
+	p.SetTrace(nil)
+	return buf.String()
+}
+
+func TestQueueRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		g      func() *grid.Grid
+		budget int // Expansions before the queue is exported.
+	}{
+		{"open, new", func() *grid.Grid { return grid.New(12, 12) }, 0},
+		{"open, mid-search", func() *grid.Grid { return grid.New(12, 12) }, 20},
+		{"maze, mid-search", func() *grid.Grid { return maze(15, 1) }, 30},
+	}
+	for _, tst := range tests {
+		// Two identical planners, only one of which has its queue replaced by
+		// the round-tripped copy.
+		var p [2]*dstarlite.Planner
+		for i := range p {
+			g := tst.g()
+			w, h := g.Size()
+			p[i] = dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: w - 1, Y: h - 1})
+			p[i].PrecomputeBudget(tst.budget)
+		}
+		snap := p[0].QueueSnapshot()
+		if len(snap) == 0 {
+			t.Fatalf("%s: queue is empty", tst.name)
+		}
+
+		var buf bytes.Buffer
+		if err := p[0].ExportQueue(coordCodec{}, &buf); err != nil {
+			t.Fatalf("%s: ExportQueue: %v", tst.name, err)
+		}
+		if err := p[0].ImportQueue(coordCodec{}, &buf); err != nil {
+			t.Fatalf("%s: ImportQueue: %v", tst.name, err)
+		}
+		if got := p[0].QueueSnapshot(); !reflect.DeepEqual(got, snap) {
+			t.Errorf("%s: imported queue %v, want %v", tst.name, got, snap)
+		}
+		if got, want := precomputeTrace(p[0]), precomputeTrace(p[1]); got != want {
+			t.Errorf("%s: imported queue expanded\n%s\nwant\n%s", tst.name, got, want)
+		}
+	}
+}
+
+func TestImportQueueTruncated(t *testing.T) {
+	p := dstarlite.New(grid.New(4, 4), grid.Coord{X: 0, Y: 0}, grid.Coord{X: 3, Y: 3})
+	p.PrecomputeBudget(3)
+	want := p.QueueSnapshot()
+	var buf bytes.Buffer
+	if err := p.ExportQueue(coordCodec{}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	if err := p.ImportQueue(coordCodec{}, bytes.NewReader(b[:len(b)-1])); err == nil {
+		t.Error("ImportQueue of truncated data returned no error")
+	}
+	if got := p.QueueSnapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("queue after failed ImportQueue is %v, want %v", got, want)
+	}
+}
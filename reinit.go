@@ -0,0 +1,109 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// reinit discards all planning progress, such that the next call to Plan
+// plans from scratch (from the current start to the current goal set) as if
+// the planner was just created. Options are kept.
+func (s *PlannerOf[S]) reinit() {
+	s.rhs.reset()
+	s.g.reset()
+	if q, ok := s.u.(*priorityQueue[S]); ok {
+		q.reset()
+	} else {
+		s.u = s.newQueue()
+	}
+	s.km = 0
+	s.canUndo = false
+	s.changed = nil
+	for g, rhs := range s.goals {
+		s.rhs[g] = rhs
+		s.u.Insert(g, s.calcKey(g))
+	}
+	s.dirty = true
+	s.fromScratch = true
+}
+
+// SetMaxKm limits the key modifier km, which grows by the distance moved on
+// every call to UpdateStart. Once an UpdateStart makes km exceed the limit,
+// the planner discards its progress and plans from scratch from the current
+// start instead, which prevents the precision of keys from silently degrading
+// over very long sessions. A limit of zero (the default) disables it.
+//
+// Each such event is counted (see Reinits) and logged to the trace writer, if
+// any (see SetTrace), as a "reinit" operation on the start state.
+func (s *PlannerOf[S]) SetMaxKm(limit float64) {
+	s.maxKm = limit
+}
+
+// Reinits returns the number of times the planner planned from scratch
+// because km exceeded the limit set by SetMaxKm.
+func (s *PlannerOf[S]) Reinits() int {
+	return s.reinits
+}
+
+// Km returns the current key modifier km, which grows by the (inflated)
+// distance moved on every call to UpdateStart and is added to the first
+// component of every key. It is reset to zero by Rebase, Reset and when the
+// limit set by SetMaxKm is exceeded.
+func (s *PlannerOf[S]) Km() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.km
+}
+
+// Reset makes the planner plan through the given data, from the given start to
+// the given goal, as if it was just created by New (or NewBottleneck, for a
+// bottleneck planner), except that its options are kept. The memory of the
+// planner is reused, which avoids allocations when solving many problems of
+// similar size (e.g. in a server handling pathfinding requests).
+func (s *PlannerOf[S]) Reset(data DataOf[S], start, goal S) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.d = data
+	s.start = start
+	s.goal = goal
+	s.goals.reset()
+	s.goals[goal] = 0
+	s.blend = nil
+	s.reinit()
+	s.expansions = 0
+	s.fullExpansions = 0
+	s.stats = Stats{}
+	s.pathCost = math.Inf(1)
+	s.history = s.history[:0]
+}
+
+// Rebase resets the key modifier km to zero without losing any planning
+// progress, by subtracting it from the keys of all queued vertices. Since km
+// grows by the distance moved on every call to UpdateStart, it otherwise
+// dominates the keys of long sessions, eroding the precision of their
+// comparison.
+//
+// Rebasing preserves the order of the queue (up to rounding), so it is safe
+// at any time between calls to Plan, and unlike SetMaxKm it does not make the
+// next call to Plan start over. It costs time proportional to n log n, for a
+// queue of n vertices.
+func (s *PlannerOf[S]) Rebase() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.km == 0 {
+		return
+	}
+	if !s.bottleneck {
+		// Bottleneck keys do not include km.
+		for _, item := range s.QueueSnapshot() {
+			if k := item.Key; !math.IsInf(k.A, 0) {
+				s.u.Update(item.State, Key{k.A - s.km, k.B})
+			}
+		}
+	}
+	s.km = 0
+	s.canUndo = false
+}
@@ -0,0 +1,213 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestSetMaxKm(t *testing.T) {
+	g := maze(21, 2)
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	p := dstarlite.New(g, start, goal)
+	p.SetMaxKm(5)
+	var buf bytes.Buffer
+	p.SetTrace(&buf)
+
+	// Every step moves a Manhattan distance of one, so km exceeds the limit
+	// on every sixth.
+	path := p.Plan()
+	for steps := 1; len(path) > 1; steps++ {
+		p.UpdateStart(path[1])
+		if want := steps / 6; p.Reinits() != want {
+			t.Fatalf("after %d steps got %d reinits, want %d", steps, p.Reinits(), want)
+		}
+		if steps%6 == 0 {
+			// Planning restarts from scratch with km reset to zero, so the
+			// only queued state is the goal, keyed by its bare distance.
+			want := dstarlite.QueueSnapshot{{goal, dstarlite.Key{A: g.Dist(path[1], goal)}}}
+			if got := p.QueueSnapshot(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("after %d steps queue is %v, want %v", steps, got, want)
+			}
+		}
+		path = p.Plan()
+		if want := dstarlite.New(g, path[0], goal).Plan(); !reflect.DeepEqual(path, want) {
+			t.Fatalf("after %d steps got path %v, want %v", steps, path, want)
+		}
+	}
+	if p.Reinits() == 0 {
+		t.Fatal("path too short to reach the km limit")
+	}
+
+	buf.Reset()
+	p.UpdateStart(start)
+	if !strings.HasPrefix(buf.String(), "reinit\t") {
+		t.Errorf("reinit was not traced, got %q", buf.String())
+	}
+}
+
+// problem is a single pathfinding problem.
+type problem struct {
+	d           dstarlite.Data
+	start, goal dstarlite.State
+}
+
+// mazeProblems returns n problems across frozen mazes of similar size.
+func mazeProblems(n int) []problem {
+	problems := make([]problem, n)
+	for i := range problems {
+		size := 31 + 2*(i%3)
+		g := maze(size, int64(i))
+		problems[i] = problem{
+			d:     dstarlite.FreezeData(g, gridCells(g)),
+			start: grid.Coord{X: 0, Y: 0},
+			goal:  grid.Coord{X: size - 1, Y: size - 1 - 2*(i%2)},
+		}
+	}
+	return problems
+}
+
+func TestResetMatchesNew(t *testing.T) {
+	problems := mazeProblems(6)
+	p := dstarlite.New(problems[0].d, problems[0].start, problems[0].goal)
+	for i, pr := range problems {
+		// Leave progress behind, which Reset must discard.
+		p.Plan()
+		p.UpdateStart(grid.Coord{X: 0, Y: 2})
+		p.Reset(pr.d, pr.start, pr.goal)
+		if !p.Dirty() {
+			t.Errorf("problem %d: planner is not dirty after Reset", i)
+		}
+		if p.Km() != 0 {
+			t.Errorf("problem %d: Km() = %v after Reset, want 0", i, p.Km())
+		}
+		fresh := dstarlite.New(pr.d, pr.start, pr.goal)
+		want, path := fresh.Plan(), p.Plan()
+		if !reflect.DeepEqual(path, want) {
+			t.Errorf("problem %d: planned %v after Reset, want %v", i, path, want)
+		}
+		if p.LastExpansions() != fresh.LastExpansions() {
+			t.Errorf("problem %d: expanded %d vertices after Reset, want %d", i, p.LastExpansions(), fresh.LastExpansions())
+		}
+	}
+}
+
+// The benchmarks below solve a series of problems on mazes of similar size,
+// with a new planner for each and with a single planner that is Reset.
+
+func BenchmarkSolveNew(b *testing.B) {
+	problems := mazeProblems(6)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pr := problems[i%len(problems)]
+		dstarlite.New(pr.d, pr.start, pr.goal).Plan()
+	}
+}
+
+func BenchmarkSolveReset(b *testing.B) {
+	problems := mazeProblems(6)
+	p := dstarlite.New(problems[0].d, problems[0].start, problems[0].goal)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pr := problems[i%len(problems)]
+		p.Reset(pr.d, pr.start, pr.goal)
+		p.Plan()
+	}
+}
+
+func TestRebase(t *testing.T) {
+	g := maze(21, 5)
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	p := dstarlite.New(g, start, goal)
+	path := p.Plan()
+	var km float64
+	for i := 0; i < 10; i++ {
+		km += g.Dist(path[0], path[1])
+		p.UpdateStart(path[1])
+		path = p.Plan()
+	}
+
+	// Rebasing lowers every key by km, keeping their order.
+	c := p.Clone()
+	before := p.QueueSnapshot()
+	if p.Km() != km {
+		t.Errorf("Km() = %v before Rebase, want %v", p.Km(), km)
+	}
+	p.Rebase()
+	if p.Km() != 0 {
+		t.Errorf("Km() = %v after Rebase, want 0", p.Km())
+	}
+	after := p.QueueSnapshot()
+	if len(after) != len(before) {
+		t.Fatalf("rebased queue has %d items, want %d", len(after), len(before))
+	}
+	for i, item := range after {
+		want := dstarlite.QueueItem{State: before[i].State, Key: dstarlite.Key{A: before[i].Key.A - km, B: before[i].Key.B}}
+		if item != want {
+			t.Errorf("rebased queue item %d is %v, want %v", i, item, want)
+		}
+	}
+
+	// Replanning is unaffected, and does not start over.
+	for i := 0; i < 10; i++ {
+		setBlocked(g, grid.Coord{X: 2*i + 1, Y: 2*i + 1}, false, p, c)
+		want := c.Plan()
+		if path = p.Plan(); !reflect.DeepEqual(path, want) {
+			t.Fatalf("replan %d after Rebase got path %v, want %v", i, path, want)
+		}
+		if p.LastExpansions() != c.LastExpansions() {
+			t.Errorf("replan %d after Rebase expanded %d vertices, want %d", i, p.LastExpansions(), c.LastExpansions())
+		}
+		p.UpdateStart(path[1])
+		c.UpdateStart(path[1])
+	}
+}
+
+// wallDistGrid is a grid whose Dist heuristic is +Inf to and from blocked
+// cells, which cannot be reached.
+type wallDistGrid struct {
+	*grid.Grid
+}
+
+func (g wallDistGrid) Dist(a, b dstarlite.State) float64 {
+	if g.Blocked(a.(grid.Coord)) || g.Blocked(b.(grid.Coord)) {
+		return math.Inf(1)
+	}
+	return g.Grid.Dist(a, b)
+}
+
+
+func TestRebaseInfiniteKm(t *testing.T) {
+	g, start, goal := parseGrid(`
+S....
+.###.
+....G
+`)
+	p := dstarlite.New(wallDistGrid{g}, start, goal)
+	p.Plan()
+
+	// Moving the start into a wall makes km +Inf, and so the keys of the
+	// states queued by a change, which Rebase must not subtract it from.
+	p.UpdateStart(grid.Coord{X: 1, Y: 1})
+	if _, err := p.PlanE(); err != dstarlite.ErrNoPath {
+		t.Errorf("got error %v from within the wall, want ErrNoPath", err)
+	}
+	setBlocked(g, grid.Coord{X: 2, Y: 1}, false, p)
+	p.Rebase()
+	for _, item := range p.QueueSnapshot() {
+		if math.IsNaN(item.Key.A) || math.IsNaN(item.Key.B) {
+			t.Errorf("key of %v is %v after Rebase", item.State, item.Key)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// Replan is like Plan, except that it also tells if the returned path differs
+// from the path returned by the previous call to Plan (or any of its variants,
+// including Replan), such that e.g. movement commands need only be re-issued
+// to agents whose route changed.
+//
+// Paths are compared state by state using State.Equals. A nil path (no path
+// was found) equals only another nil path, so the path is considered changed
+// when a path is found where there was none before and vice versa, but not
+// when there is still no path. Before the first call to Plan (and after Reset
+// or Clone) the previous path is considered nil. Calls stopped early, e.g. by
+// the expansion limit (see SetMaxExpansions), return nil but are otherwise
+// ignored.
+func (s *PlannerOf[S]) Replan() ([]S, bool) {
+	prev := s.lastPath()
+	path := s.Plan()
+	return path, !s.pathsEqual(prev, path)
+}
+
+// PlanDiff is like Plan, except that it also returns the number of leading
+// states of the path that are equal (by State.Equals) to those of the path
+// returned by the previous call to Plan, under the same rules as Replan. Only
+// path[commonPrefix:] differs from the previous path, so e.g. only that tail
+// needs to be sent to an agent already following the previous path.
+//
+// If the start was moved along the previous path (see UpdateStart), the new
+// path is compared with the remainder of the previous path from the new start
+// on. If the previous path is nil, no path is found, or the start is not on
+// the previous path, commonPrefix is zero.
+func (s *PlannerOf[S]) PlanDiff() (path []S, commonPrefix int) {
+	prev := s.lastPath()
+	path = s.Plan()
+	if len(path) == 0 {
+		return path, 0
+	}
+	for i, st := range prev {
+		if s.eq(st, path[0]) {
+			prev = prev[i:]
+			break
+		}
+	}
+	for commonPrefix < len(path) && commonPrefix < len(prev) && s.eq(path[commonPrefix], prev[commonPrefix]) {
+		commonPrefix++
+	}
+	return path, commonPrefix
+}
+
+// lastPath returns the path returned by the previous call to Plan, see
+// Replan.
+func (s *PlannerOf[S]) lastPath() []S {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if n := len(s.history); n > 0 {
+		return s.history[n-1]
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestReplan(t *testing.T) {
+	g := graph{{"a", "b"}: 1, {"b", "c"}: 1, {"a", "d"}: 2, {"d", "c"}: 2}
+	p := dstarlite.New(g, node("a"), node("c"))
+
+	// set changes the cost of an edge and flags the change to the planner.
+	set := func(from, to node, cost float64) {
+		e := [2]node{from, to}
+		old := g[e]
+		g[e] = cost
+		p.FlagChanged(from, to, old, cost)
+	}
+	steps := []struct {
+		name    string
+		change  func()
+		length  int // Zero if there is no path.
+		changed bool
+	}{
+		{"first plan", func() {}, 3, true},
+		{"unchanged", func() {}, 3, false},
+		{"blocked", func() { set("a", "b", math.Inf(1)) }, 3, true},
+		{"no path", func() { set("a", "d", math.Inf(1)) }, 0, true},
+		{"still no path", func() {}, 0, false},
+		{"unblocked", func() { set("a", "b", 1) }, 3, true},
+		{"costlier detour", func() { set("a", "d", 5) }, 3, false},
+	}
+	for _, st := range steps {
+		st.change()
+		path, changed := p.Replan()
+		if len(path) != st.length || changed != st.changed {
+			t.Errorf("%s: got path %v changed %v, want %d states changed %v", st.name, path, changed, st.length, st.changed)
+		}
+	}
+}
+
+func TestPlanDiff(t *testing.T) {
+	g := graph{{"a", "b"}: 1, {"b", "c"}: 1, {"c", "d"}: 1, {"d", "e"}: 1, {"c", "x"}: 2, {"x", "e"}: 2, {"y", "c"}: 1}
+	p := dstarlite.New(g, node("a"), node("e"))
+	steps := []struct {
+		name   string
+		change func()
+		path   []dstarlite.State
+		common int
+	}{
+		{"first plan", func() {}, nodes("a", "b", "c", "d", "e"), 0},
+		{"unchanged", func() {}, nodes("a", "b", "c", "d", "e"), 5},
+		{"detour", func() {
+			g[[2]node{"d", "e"}] = math.Inf(1)
+			p.FlagChanged(node("d"), node("e"), 1, math.Inf(1))
+		}, nodes("a", "b", "c", "x", "e"), 3},
+		{"start moved along path", func() { p.UpdateStart(node("b")) }, nodes("b", "c", "x", "e"), 4},
+		{"start moved off path", func() { p.UpdateStart(node("y")) }, nodes("y", "c", "x", "e"), 0},
+	}
+	for _, st := range steps {
+		st.change()
+		path, common := p.PlanDiff()
+		if !reflect.DeepEqual(path, st.path) || common != st.common {
+			t.Errorf("%s: got path %v with %d common states, want %v with %d", st.name, path, common, st.path, st.common)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestReuseRatio(t *testing.T) {
+	g := maze(31, 5)
+	// Open up the maze, such that there is a detour around every cell.
+	for y := 1; y < 31; y += 4 {
+		for x := 0; x < 31; x++ {
+			g.SetBlocked(grid.Coord{X: x, Y: y}, false)
+		}
+	}
+	p := dstarlite.New(g, grid.Coord{X: 0, Y: 0}, grid.Coord{X: 30, Y: 30})
+	if r := p.ReuseRatio(); r != 0 {
+		t.Errorf("ReuseRatio() = %v before Plan, want 0", r)
+	}
+	path := p.Plan()
+	if r := p.ReuseRatio(); r != 1 {
+		t.Errorf("ReuseRatio() = %v after the first Plan, want 1", r)
+	}
+
+	// Block the path close to the goal, such that only a short detour needs
+	// to be planned.
+	setBlocked(g, path[len(path)-3].(grid.Coord), true, p)
+	if p.Plan() == nil {
+		t.Fatal("no path found after the change")
+	}
+	if r := p.ReuseRatio(); r <= 0 || r > 0.25 {
+		t.Errorf("ReuseRatio() = %v after a small change, want it in (0, 0.25]", r)
+	}
+	p.Plan()
+	if r := p.ReuseRatio(); r != 0 {
+		t.Errorf("ReuseRatio() = %v after Plan without changes, want 0", r)
+	}
+}
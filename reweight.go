@@ -0,0 +1,114 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNegativeCycle is returned by Reweight when the graph contains a cycle
+// whose total cost is negative, in which case no shortest path exists.
+var ErrNegativeCycle = errors.New("dstarlite: negative cost cycle")
+
+// Reweighted wraps a Data interface whose edge costs may be negative such
+// that every edge cost is non-negative, as D* Lite requires. It is returned by
+// Reweight and implements the Data interface itself.
+//
+// Each state s is given a potential p(s) and the cost of an edge from u to v
+// becomes:
+//
+//  Cost(u, v) + p(u) - p(v)
+//
+// The potentials of the inner states of any path cancel out, so shortest
+// paths are unchanged and only their total cost is offset, see OriginalCost.
+type Reweighted struct {
+	d         Data
+	potential map[State]float64
+}
+
+// Potential returns the potential of the specified state.
+func (r *Reweighted) Potential(s State) float64 {
+	return r.potential[s]
+}
+
+// OriginalCost translates the reweighted cost of a path from start to goal
+// back into its cost under the original Data interface.
+func (r *Reweighted) OriginalCost(cost float64, start, goal State) float64 {
+	return cost - r.potential[start] + r.potential[goal]
+}
+
+// Succ implements the Data interface.
+func (r *Reweighted) Succ(s State) []State {
+	return r.d.Succ(s)
+}
+
+// Pred implements the Data interface.
+func (r *Reweighted) Pred(s State) []State {
+	return r.d.Pred(s)
+}
+
+// Dist implements the Data interface. The Dist of the original Data interface
+// can't be trusted to be admissible once costs are negative, so it always
+// returns zero and the planner expands states like Dijkstra's algorithm.
+func (r *Reweighted) Dist(a, b State) float64 {
+	return 0
+}
+
+// Cost implements the Data interface.
+func (r *Reweighted) Cost(a, b State) float64 {
+	c := r.d.Cost(a, b)
+	if math.IsInf(c, 1) {
+		return c
+	}
+	// Clamp away tiny negative values due to floating point error.
+	return math.Max(0, c+r.potential[a]-r.potential[b])
+}
+
+// Reweight computes Johnson-style potentials (using the Bellman-Ford
+// algorithm) that make every edge cost between the given states
+// non-negative, and returns the reweighted Data interface.
+//
+// The states must include every state that a planner may visit. If the graph
+// has a negative cost cycle, ErrNegativeCycle is returned.
+func Reweight(d Data, states []State) (*Reweighted, error) {
+	p := make(map[State]float64, len(states))
+	for _, s := range states {
+		// Distances from a virtual source with a zero cost edge to every
+		// state.
+		p[s] = 0
+	}
+
+	relax := func() bool {
+		changed := false
+		for _, u := range states {
+			for _, v := range d.Succ(u) {
+				pv, ok := p[v]
+				if !ok {
+					continue
+				}
+				c := d.Cost(u, v)
+				if math.IsInf(c, 1) {
+					continue
+				}
+				if p[u]+c < pv {
+					p[v] = p[u] + c
+					changed = true
+				}
+			}
+		}
+		return changed
+	}
+
+	for i := 0; i < len(states); i++ {
+		if !relax() {
+			return &Reweighted{d, p}, nil
+		}
+	}
+	if relax() {
+		return nil, ErrNegativeCycle
+	}
+	return &Reweighted{d, p}, nil
+}
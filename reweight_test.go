@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+// states returns the states of the graph, i.e. those of its edges.
+func (g graph) states() []dstarlite.State {
+	seen := make(map[node]bool)
+	var states []dstarlite.State
+	for e := range g {
+		for _, n := range e {
+			if !seen[n] {
+				seen[n] = true
+				states = append(states, n)
+			}
+		}
+	}
+	return states
+}
+
+func TestReweight(t *testing.T) {
+	tests := []struct {
+		name string
+		g    graph
+		path []dstarlite.State // nil if there is a negative cycle.
+		cost float64
+	}{
+		{
+			name: "non-negative",
+			g:    graph{{"a", "b"}: 1, {"b", "d"}: 1, {"a", "c"}: 1, {"c", "d"}: 2},
+			path: nodes("a", "b", "d"),
+			cost: 2,
+		},
+		{
+			name: "negative shortcut",
+			g:    graph{{"a", "b"}: 4, {"a", "c"}: 2, {"c", "b"}: -3, {"b", "d"}: 1},
+			path: nodes("a", "c", "b", "d"),
+			cost: 0,
+		},
+		{
+			name: "negative edge behind expensive one",
+			g:    graph{{"a", "b"}: 1, {"b", "d"}: 1, {"a", "c"}: 5, {"c", "d"}: -10},
+			path: nodes("a", "c", "d"),
+			cost: -5,
+		},
+		{
+			name: "negative cycle",
+			g:    graph{{"a", "b"}: 1, {"b", "a"}: -2, {"b", "d"}: 1},
+		},
+	}
+	for _, tst := range tests {
+		r, err := dstarlite.Reweight(tst.g, tst.g.states())
+		if tst.path == nil {
+			if err != dstarlite.ErrNegativeCycle {
+				t.Errorf("%s: got error %v, want ErrNegativeCycle", tst.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tst.name, err)
+		}
+		for e := range tst.g {
+			if c := r.Cost(e[0], e[1]); c < 0 {
+				t.Errorf("%s: reweighted cost of %v is %v", tst.name, e, c)
+			}
+		}
+		path := dstarlite.New(r, node("a"), node("d")).Plan()
+		if !reflect.DeepEqual(path, tst.path) {
+			t.Errorf("%s: got path %v, want %v", tst.name, path, tst.path)
+		}
+		cost := 0.0
+		for i := 1; i < len(path); i++ {
+			cost += r.Cost(path[i-1], path[i])
+		}
+		if cost := r.OriginalCost(cost, node("a"), node("d")); !costsEqual(cost, tst.cost) {
+			t.Errorf("%s: OriginalCost() = %v, want %v", tst.name, cost, tst.cost)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// Smooth removes the waypoints of a path (as returned by Plan) that can be
+// skipped by moving in a straight line: starting at the first state, each
+// kept state is followed by the furthest later state that los reports to be
+// in line of sight of it, even if some state in between is not (e.g. behind a
+// pillar). The first and last states are always kept. Finding the furthest
+// state calls los up to once per later state, so smoothing a path of n states
+// makes O(n^2) calls in the worst case.
+//
+// The los function should tell if the straight line between two states is
+// unobstructed; see e.g. grid.LineOfSight. The smoothed path no longer
+// consists of neighboring states, so it is meant for steering an agent rather
+// than for the Data interface.
+func Smooth(path []State, los func(a, b State) bool) []State {
+	if len(path) < 3 {
+		return append([]State(nil), path...)
+	}
+	smooth := []State{path[0]}
+	anchor := 0
+	for anchor < len(path)-1 {
+		next := len(path) - 1
+		for next > anchor+1 && !los(path[anchor], path[next]) {
+			next--
+		}
+		smooth = append(smooth, path[next])
+		anchor = next
+	}
+	return smooth
+}
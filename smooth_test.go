@@ -0,0 +1,61 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestSmooth(t *testing.T) {
+	tests := []struct {
+		name string
+		m    string
+		want []dstarlite.State
+	}{
+		{"open", `
+S....
+.....
+....G
+`, []dstarlite.State{grid.Coord{X: 0, Y: 0}, grid.Coord{X: 4, Y: 2}}},
+		{"corner", `
+S....
+####.
+....G
+`, []dstarlite.State{grid.Coord{X: 0, Y: 0}, grid.Coord{X: 4, Y: 0}, grid.Coord{X: 4, Y: 2}}},
+		{"diagonal past corner", `
+S#.
+...
+..G
+`, []dstarlite.State{grid.Coord{X: 0, Y: 0}, grid.Coord{X: 0, Y: 1}, grid.Coord{X: 2, Y: 2}}},
+	}
+	for _, tst := range tests {
+		g, start, goal := parseGrid(tst.m)
+		path := dstarlite.New(g, start, goal).Plan()
+		got := dstarlite.Smooth(path, grid.LineOfSight(g))
+		if !reflect.DeepEqual(got, tst.want) {
+			t.Errorf("%s: smoothed %v to %v, want %v", tst.name, path, got, tst.want)
+		}
+	}
+}
+
+func TestSmoothSkipsHiddenStates(t *testing.T) {
+	// The line of sight from a to b and c is blocked (e.g. by a pillar), but
+	// that to d is not.
+	visible := map[[2]node]bool{{"a", "d"}: true, {"d", "e"}: true}
+	los := func(a, b dstarlite.State) bool { return visible[[2]node{a.(node), b.(node)}] }
+	path := nodes("a", "b", "c", "d", "e")
+	if got, want := dstarlite.Smooth(path, los), nodes("a", "d", "e"); !reflect.DeepEqual(got, want) {
+		t.Errorf("smoothed %v to %v, want %v", path, got, want)
+	}
+	for _, path := range [][]dstarlite.State{nil, nodes("a"), nodes("a", "b")} {
+		if got := dstarlite.Smooth(path, los); !reflect.DeepEqual(got, path) {
+			t.Errorf("smoothed %v to %v, want it unchanged", path, got)
+		}
+	}
+}
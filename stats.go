@@ -0,0 +1,30 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// Stats describes the work done by a single call to Plan.
+type Stats struct {
+	// Number of vertices popped from the priority queue and expanded.
+	Expanded int
+
+	// Number of vertices whose queue membership was checked (and possibly
+	// changed) after their g or rhs value changed.
+	VertexUpdates int
+
+	// Number of vertices inserted into and removed from the priority queue.
+	QueueInserts, QueueRemoves int
+
+	// Number of states in the path returned, zero if none was found.
+	PathLength int
+}
+
+// Stats returns the statistics of the last call to Plan, which is useful e.g.
+// for comparing incremental replans against planning from scratch. They are
+// reset at the start of every call to Plan.
+func (s *PlannerOf[S]) Stats() Stats {
+	st := s.stats
+	st.Expanded = s.expansions
+	return st
+}
@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"context"
+)
+
+// PlanStream is like Plan, except that the states of the path are sent on the
+// returned channel as the gradient walk produces them, such that the path can
+// be consumed incrementally (and abandoned early). The channel is closed once
+// the goal was sent, or without sending anything if there is no path; if the
+// walk fails midway (see PlanE) it is closed after the states walked so far.
+//
+// Planning happens in a separate goroutine, which holds the lock of the
+// planner (see Planner) until the channel is closed. To stop reading early,
+// cancel ctx: the goroutine then stops and closes the channel. A nil ctx is
+// treated as context.Background(), which can't be canceled.
+func (s *PlannerOf[S]) PlanStream(ctx context.Context) <-chan S {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ch := make(chan S)
+	go func() {
+		defer close(ch)
+		s.plan(ctx, func(st S) error {
+			select {
+			case ch <- st:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return ch
+}
@@ -0,0 +1,50 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestPlanStream(t *testing.T) {
+	g, start, goal := parseGrid(`
+S..#....
+.#.#.##.
+.#...#..
+.####.#G
+`)
+	want := dstarlite.New(g, start, goal).Plan()
+	var got []dstarlite.State
+	for st := range dstarlite.New(g, start, goal).PlanStream(nil) {
+		got = append(got, st)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("streamed path %v, want %v", got, want)
+	}
+
+	// Abandoning the stream after the first state releases the planner.
+	p := dstarlite.New(g, start, goal)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := p.PlanStream(ctx)
+	if st := <-ch; st != start {
+		t.Errorf("stream started at %v, want %v", st, start)
+	}
+	cancel()
+	for range ch {
+	}
+	if path := p.Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("after canceling the stream Plan returned %v, want %v", path, want)
+	}
+
+	// Without a path the channel is closed without sending anything.
+	g, start, goal = parseGrid("S#G")
+	for st := range dstarlite.New(g, start, goal).PlanStream(context.Background()) {
+		t.Errorf("streamed %v without a path", st)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// decimalGrid is a grid whose cells cost decimal fractions to enter, which are
+// not exactly representable, such that the g and rhs values reached through
+// different paths differ by rounding error.
+type decimalGrid struct {
+	*grid.Grid
+	weights []float64
+}
+
+func (d decimalGrid) Dist(a, b dstarlite.State) float64 {
+	return 0.1 * d.Grid.Dist(a, b)
+}
+
+func (d decimalGrid) Cost(a, b dstarlite.State) float64 {
+	w, _ := d.Size()
+	bc := b.(grid.Coord)
+	return d.Grid.Cost(a, b) * d.weights[bc.Y*w+bc.X]
+}
+
+// replanExpansions returns the total number of states expanded over many
+// replans of a decimal grid, as its cells are toggled at random.
+func replanExpansions(tolerance float64) int {
+	defer func(old float64) { dstarlite.Tolerance = old }(dstarlite.Tolerance)
+	dstarlite.Tolerance = tolerance
+
+	const size = 40
+	rng := rand.New(rand.NewSource(1))
+	d := decimalGrid{grid.New(size, size), make([]float64, size*size)}
+	for i := range d.weights {
+		d.weights[i] = []float64{0.1, 0.2, 0.3, 0.7}[rng.Intn(4)]
+	}
+	p := dstarlite.New(d, grid.Coord{X: 0, Y: 0}, grid.Coord{X: size - 1, Y: size - 1})
+	p.Plan()
+
+	expansions := 0
+	for i := 0; i < 50; i++ {
+		c := grid.Coord{X: rng.Intn(size), Y: rng.Intn(size)}
+		type edge struct {
+			u, v dstarlite.State
+			cost float64
+		}
+		var edges []edge
+		for _, n := range d.Succ(c) {
+			edges = append(edges, edge{c, n, d.Cost(c, n)}, edge{n, c, d.Cost(n, c)})
+		}
+		d.SetBlocked(c, !d.Blocked(c))
+		for _, e := range edges {
+			p.FlagChanged(e.u, e.v, e.cost, d.Cost(e.u, e.v))
+		}
+		p.Plan()
+		expansions += p.LastExpansions()
+	}
+	return expansions
+}
+
+func TestToleranceAvoidsRequeueing(t *testing.T) {
+	// Compared exactly, values that differ only by rounding error make states
+	// needlessly move in and out of the priority queue.
+	exact, tolerant := replanExpansions(0), replanExpansions(dstarlite.Tolerance)
+	if tolerant >= exact {
+		t.Errorf("replans expanded %d states with tolerance, want fewer than %d without", tolerant, exact)
+	}
+}
+
+// skewedFan is a fan whose edges from each leaf to the hub cost one plus the
+// leaf's skew.
+type skewedFan struct {
+	fan
+	skew map[node]float64
+}
+
+func (d skewedFan) Cost(a, b dstarlite.State) float64 {
+	return 1 + d.skew[a.(node)]
+}
+
+func TestKeyTolerance(t *testing.T) {
+	defer func(old float64) { dstarlite.KeyTolerance = old }(dstarlite.KeyTolerance)
+	d := skewedFan{
+		fan:  fan{"a", "b", "c", "d", "e"},
+		skew: map[node]float64{"a": 5e-12, "b": 4e-12, "c": 3e-12, "d": 2e-12, "e": 1e-12},
+	}
+	tests := []struct {
+		tolerance float64
+		want      fan
+	}{
+		// The leaves are expanded in the order of their keys.
+		{0, fan{"e", "d", "c", "b", "a"}},
+		// Their keys are considered equal, so they are expanded in the order
+		// they were queued.
+		{1e-9, fan{"a", "b", "c", "d", "e"}},
+	}
+	for _, tst := range tests {
+		dstarlite.KeyTolerance = tst.tolerance
+		p := dstarlite.New(d, node("start"), node("hub"))
+		var order fan
+		p.OnExpand(func(st dstarlite.State, k dstarlite.Key, overconsistent bool) {
+			if st != node("start") && st != node("hub") {
+				order = append(order, st.(node))
+			}
+		})
+		p.Plan()
+		if !reflect.DeepEqual(order, tst.want) {
+			t.Errorf("tolerance %v: leaves expanded in order %v, want %v", tst.tolerance, order, tst.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetTrace makes the planner write a log line to w for every operation it
+// performs, which is useful for post-mortem analysis of a tricky replan. The
+// log is very verbose and intended only for debugging. Passing nil (the
+// default) disables tracing.
+//
+// Each line consists of six tab-separated fields:
+//
+//  op  state  keyA  keyB  g  rhs
+//
+// Where op is one of:
+//
+//  expand - the state was popped from the top of the priority queue.
+//  insert - the state was inserted into the priority queue.
+//  update - the key of the state in the priority queue was changed.
+//  remove - the state was removed from the priority queue.
+//  g      - the g value of the state was changed.
+//  rhs    - the rhs value of the state was changed.
+//  reinit - planning restarted from scratch at the state, see SetMaxKm.
+//
+// The state is formatted using the %v verb of the fmt package, the key is the
+// current key of the state and g and rhs are its values after the operation.
+// Write errors are ignored.
+func (s *PlannerOf[S]) SetTrace(w io.Writer) {
+	s.traceW = w
+}
+
+func (s *PlannerOf[S]) trace(op string, st S) {
+	if s.traceW == nil {
+		return
+	}
+	k := s.calcKey(st)
+	fmt.Fprintf(s.traceW, "%s\t%v\t%v\t%v\t%v\t%v\n", op, st, k.A, k.B, s.g.get(st), s.rhs.get(st))
+}
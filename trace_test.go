@@ -0,0 +1,79 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+// traceLine is a parsed line of the log written by SetTrace.
+type traceLine struct {
+	op, state  string
+	keyA, keyB float64
+	g, rhs     float64
+}
+
+func parseTrace(t *testing.T, log string) []traceLine {
+	var lines []traceLine
+	for _, l := range strings.Split(strings.TrimSuffix(log, "\n"), "\n") {
+		f := strings.Split(l, "\t")
+		if len(f) != 6 {
+			t.Fatalf("trace line %q has %d fields, want 6", l, len(f))
+		}
+		var v [4]float64
+		for i := range v {
+			var err error
+			if v[i], err = strconv.ParseFloat(f[i+2], 64); err != nil {
+				t.Fatalf("trace line %q: %v", l, err)
+			}
+		}
+		lines = append(lines, traceLine{f[0], f[1], v[0], v[1], v[2], v[3]})
+	}
+	return lines
+}
+
+func TestTrace(t *testing.T) {
+	g, start, goal := parseGrid("S.G")
+	p := dstarlite.New(g, start, goal)
+	var buf bytes.Buffer
+	p.SetTrace(&buf)
+	p.Plan()
+
+	inf := math.Inf(1)
+	want := []traceLine{
+		{"expand", "{2 0}", 2, 0, inf, 0},
+		{"g", "{2 0}", 2, 0, 0, 0},
+		{"remove", "{2 0}", 2, 0, 0, 0},
+		{"rhs", "{1 0}", 2, 1, inf, 1},
+		{"insert", "{1 0}", 2, 1, inf, 1},
+		{"expand", "{1 0}", 2, 1, inf, 1},
+		{"g", "{1 0}", 2, 1, 1, 1},
+		{"remove", "{1 0}", 2, 1, 1, 1},
+		{"rhs", "{0 0}", 2, 2, inf, 2},
+		{"insert", "{0 0}", 2, 2, inf, 2},
+	}
+	if got := parseTrace(t, buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got trace\n%s\nwant %v", buf.String(), want)
+	}
+
+	// Without changes Plan performs no operations, and nothing is written
+	// once tracing is disabled.
+	buf.Reset()
+	p.Plan()
+	p.SetTrace(nil)
+	p.UpdateStart(grid.Coord{X: 1, Y: 0})
+	p.Plan()
+	if buf.Len() != 0 {
+		t.Errorf("got trace %q, want none", buf.String())
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// TreeHeight returns the largest number of hops from any of the given states
+// to the goal, following the best successor of each state like Plan does.
+// States whose g value is not known (i.e. that have not been settled by a
+// previous call to Plan or Precompute) are skipped.
+//
+// In a valid shortest path tree the chains never cycle, but as a guard each
+// chain is abandoned once it is longer than the number of states with a known
+// g value.
+func (s *PlannerOf[S]) TreeHeight(states []S) int {
+	limit := len(s.g)
+	height := 0
+	for _, st := range states {
+		if math.IsInf(s.g.get(st), 1) {
+			continue
+		}
+		hops := 0
+		ok := true
+		for ok && !s.isGoal(st) && hops <= limit {
+			st, ok = s.next(st)
+			hops++
+		}
+		if ok && s.isGoal(st) && hops > height {
+			height = hops
+		}
+	}
+	return height
+}
@@ -0,0 +1,44 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestTreeHeight(t *testing.T) {
+	// A winding corridor, whose far end is 16 hops from the goal.
+	g, start, goal := parseGrid(`
+G....
+####.
+.....
+.####
+....S
+`)
+	p := dstarlite.New(g, start, goal)
+	cells := gridCells(g)
+	if h := p.TreeHeight(cells); h != 0 {
+		t.Errorf("TreeHeight() = %d before planning, want 0", h)
+	}
+	p.Precompute()
+	tests := []struct {
+		states []dstarlite.State
+		want   int
+	}{
+		{cells, 16},
+		{[]dstarlite.State{grid.Coord{X: 4, Y: 0}}, 4},
+		{[]dstarlite.State{goal, grid.Coord{X: 2, Y: 2}}, 8},
+		{[]dstarlite.State{grid.Coord{X: 0, Y: 1}}, 0},
+		{nil, 0},
+	}
+	for _, tst := range tests {
+		if h := p.TreeHeight(tst.states); h != tst.want {
+			t.Errorf("TreeHeight(%v) = %d, want %d", tst.states, h, tst.want)
+		}
+	}
+}
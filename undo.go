@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"errors"
+)
+
+// ErrNoUndo is returned by UndoUpdateStart when there is no call to
+// UpdateStart to undo.
+var ErrNoUndo = errors.New("dstarlite: no UpdateStart to undo")
+
+// UndoUpdateStart reverts the last call to UpdateStart, restoring the previous
+// start and key modifier km, e.g. after a speculative move. Only one level of
+// undo is kept: a second call returns ErrNoUndo, as does a call before any
+// UpdateStart. Since they change km, Rebase, Reset and a restart caused by the
+// limit set by SetMaxKm discard the undo as well.
+//
+// Planning work done since the UpdateStart is kept. As the keys of the states
+// queued meanwhile may then overestimate their new keys, which the algorithm
+// does not allow, the whole priority queue is re-keyed, which costs time
+// proportional to n log n for a queue of n vertices.
+func (s *PlannerOf[S]) UndoUpdateStart() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.canUndo {
+		return ErrNoUndo
+	}
+	s.start, s.km = s.undoStart, s.undoKm
+	s.canUndo = false
+	for _, item := range s.QueueSnapshot() {
+		s.u.Update(item.State, s.calcKey(item.State))
+	}
+	s.dirty = true
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestUndoUpdateStart(t *testing.T) {
+	g := maze(21, 3)
+	start, goal := grid.Coord{X: 0, Y: 0}, grid.Coord{X: 20, Y: 20}
+	p := dstarlite.New(g, start, goal)
+	if err := p.UndoUpdateStart(); err != dstarlite.ErrNoUndo {
+		t.Errorf("undo before UpdateStart: got error %v, want ErrNoUndo", err)
+	}
+	want := p.Plan()
+
+	// A speculative move that is planned from and then undone.
+	p.UpdateStart(want[2])
+	p.Plan()
+	if err := p.UndoUpdateStart(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Start() != start || p.Km() != 0 {
+		t.Errorf("after undo got start %v and Km() %v, want %v and 0", p.Start(), p.Km(), start)
+	}
+	if err := p.UndoUpdateStart(); err != dstarlite.ErrNoUndo {
+		t.Errorf("second undo: got error %v, want ErrNoUndo", err)
+	}
+	if path := p.Plan(); !reflect.DeepEqual(path, want) {
+		t.Errorf("after undo planned %v, want %v", path, want)
+	}
+
+	// The re-keyed queue stays valid as the maze changes.
+	g.SetPlanner(p)
+	for i := 0; i < 5; i++ {
+		c := grid.Coord{X: 4*i + 1, Y: 4*i + 1}
+		g.SetBlocked(c, !g.Blocked(c))
+		got := p.Plan()
+		if fresh := dstarlite.New(g, start, goal).Plan(); !costsEqual(dstarlite.PathCost(g, got), dstarlite.PathCost(g, fresh)) {
+			t.Errorf("change %d: planned %v, want a path as cheap as %v", i, got, fresh)
+		}
+	}
+
+	// Rebase discards the undo.
+	p.UpdateStart(want[2])
+	p.Rebase()
+	if err := p.UndoUpdateStart(); err != dstarlite.ErrNoUndo {
+		t.Errorf("undo after Rebase: got error %v, want ErrNoUndo", err)
+	}
+}
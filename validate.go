@@ -0,0 +1,69 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"fmt"
+	"math"
+)
+
+// ValidateData checks that the Data interface meets the assumptions of the
+// planner (see Data) for the given sample of states, and returns an error
+// describing the first violation found, or nil. The following are checked for
+// every state a in the sample:
+//
+//  Dist(a, a) == 0
+//  Cost(a, c) >= 0, for every successor c of a
+//  a is a predecessor of each of its successors, and vice versa
+//  Dist(a, b) <= Cost(a, c) + Dist(c, b), for every b in the sample and
+//  every successor c of a
+//
+// The last check takes time quadratic in the sample size. Small floating
+// point errors (see Tolerance) are ignored.
+func ValidateData(d Data, states []State) error {
+	for _, a := range states {
+		if h := d.Dist(a, a); h != 0 {
+			return fmt.Errorf("dstarlite: Dist(%v, %v) = %v, want 0", a, a, h)
+		}
+		for _, c := range d.Succ(a) {
+			if cost := d.Cost(a, c); !(cost >= 0) {
+				return fmt.Errorf("dstarlite: Cost(%v, %v) = %v, want >= 0", a, c, cost)
+			}
+			if !containsState(d.Pred(c), a) {
+				return fmt.Errorf("dstarlite: %v is a successor of %v, but not the reverse predecessor", c, a)
+			}
+		}
+		for _, c := range d.Pred(a) {
+			if !containsState(d.Succ(c), a) {
+				return fmt.Errorf("dstarlite: %v is a predecessor of %v, but not the reverse successor", c, a)
+			}
+		}
+	}
+	for _, a := range states {
+		for _, c := range d.Succ(a) {
+			cost := d.Cost(a, c)
+			if math.IsInf(cost, 1) {
+				continue
+			}
+			for _, b := range states {
+				lhs, rhs := d.Dist(a, b), cost+d.Dist(c, b)
+				if lhs > rhs && !float64Equals(lhs, rhs) {
+					return fmt.Errorf("dstarlite: Dist(%v, %v) = %v exceeds Cost(%v, %v) + Dist(%v, %v) = %v", a, b, lhs, a, c, c, b, rhs)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// containsState tells if st is in the slice, according to State.Equals.
+func containsState(states []State, st State) bool {
+	for _, s := range states {
+		if s.Equals(st) {
+			return true
+		}
+	}
+	return false
+}
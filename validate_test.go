@@ -0,0 +1,50 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestValidateData(t *testing.T) {
+	g := maze(9, 1)
+	valid := dstarlite.FuncData{SuccFn: g.Succ, PredFn: g.Pred, DistFn: g.Dist, CostFn: g.Cost}
+	tests := []struct {
+		name  string
+		spoil func(d *dstarlite.FuncData)
+		ok    bool
+	}{
+		{"valid", func(d *dstarlite.FuncData) {}, true},
+		{"negative cost", func(d *dstarlite.FuncData) {
+			d.CostFn = func(a, b dstarlite.State) float64 { return -g.Cost(a, b) }
+		}, false},
+		{"missing predecessor", func(d *dstarlite.FuncData) {
+			d.PredFn = func(s dstarlite.State) []dstarlite.State { return nil }
+		}, false},
+		{"missing successor", func(d *dstarlite.FuncData) {
+			d.SuccFn = func(s dstarlite.State) []dstarlite.State { return nil }
+		}, false},
+		{"nonzero self distance", func(d *dstarlite.FuncData) {
+			d.DistFn = func(a, b dstarlite.State) float64 { return g.Dist(a, b) + 1 }
+		}, false},
+		{"inconsistent distance", func(d *dstarlite.FuncData) {
+			d.DistFn = func(a, b dstarlite.State) float64 { return 3 * g.Dist(a, b) }
+		}, false},
+	}
+	for _, tst := range tests {
+		d := valid
+		tst.spoil(&d)
+		err := dstarlite.ValidateData(d, gridCells(g))
+		if (err == nil) != tst.ok {
+			t.Errorf("%s: got error %v, want ok %v", tst.name, err, tst.ok)
+		}
+	}
+	if err := dstarlite.ValidateData(g, []dstarlite.State{grid.Coord{X: 0, Y: 0}}); err != nil {
+		t.Errorf("single state: got error %v, want nil", err)
+	}
+}
@@ -9,14 +9,21 @@ import (
 )
 
 // Just a small helper type.
-type valueMap map[State]float64
+type valueMap[S comparable] map[S]float64
 
 // Get returns the specified key in the map, or if the specified key does not
 // exist returns +Inf
-func (v valueMap) get(s State) float64 {
+func (v valueMap[S]) get(s S) float64 {
 	val, ok := v[s]
 	if !ok {
 		return math.Inf(1)
 	}
 	return val
 }
+
+// reset removes all entries, keeping the allocated memory.
+func (v valueMap[S]) reset() {
+	for s := range v {
+		delete(v, s)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+import (
+	"math"
+)
+
+// AddVertex introduces a state that is new to the search space, e.g. a cell
+// of a map that grows as it is explored. The Data interface must already
+// report the state and its edges (through Succ, Pred and Cost, in both
+// directions) when AddVertex is called. The state then participates in the
+// next call to Plan like any other.
+//
+// Edges from existing states into the new state need not be flagged through
+// FlagChanged: they are accounted for once the planner expands the new state.
+func (s *PlannerOf[S]) AddVertex(st S) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isGoal(st) {
+		s.rhs[st] = s.minRhsExcept(st, nil)
+		s.trace("rhs", st)
+	}
+	s.updateVertex(st)
+	s.dirty = true
+}
+
+// RemoveVertex removes a state from the search space, discarding its g and
+// rhs values and its queue entry, and replanning the states that reached the
+// goal through it. If it is a goal it is removed from the goal set as well,
+// see RemoveGoal.
+//
+// The Data interface must still report the state and its edges when
+// RemoveVertex is called (such that its predecessors can be found), and must
+// stop reporting them afterwards.
+func (s *PlannerOf[S]) RemoveVertex(st S) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isGoal(st) {
+		s.removeGoal(st)
+	}
+	gOld := s.g.get(st)
+	delete(s.g, st)
+	delete(s.rhs, st)
+	if s.u.Contains(st) {
+		s.u.Remove(st)
+		s.trace("remove", st)
+	}
+
+	except := map[S]bool{st: true}
+	for _, p := range s.pred(st) {
+		if s.isGoal(p) {
+			continue
+		}
+		if float64Equals(s.rhs.get(p), s.combine(s.d.Cost(p, st), gOld)) {
+			s.rhs[p] = s.minRhsExcept(p, except)
+			s.trace("rhs", p)
+			s.updateVertex(p)
+		}
+	}
+	s.dirty = true
+}
+
+// minRhsExcept returns the lowest value of reaching the goal from st through
+// any of its successors not in the except set.
+func (s *PlannerOf[S]) minRhsExcept(st S, except map[S]bool) float64 {
+	minRhs := math.Inf(1)
+	for _, sPrime := range s.succ(st) {
+		if except[sPrime] {
+			continue
+		}
+		rhsPrime := s.combine(s.d.Cost(st, sPrime), s.g.get(sPrime))
+		if rhsPrime < minRhs {
+			minRhs = rhsPrime
+		}
+	}
+	return minRhs
+}
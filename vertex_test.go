@@ -0,0 +1,42 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+)
+
+func TestAddRemoveVertex(t *testing.T) {
+	g := graph{
+		{"a", "b"}: 1,
+		{"b", "c"}: 5,
+	}
+	p := dstarlite.New(g, node("a"), node("c"))
+	if path, want := p.Plan(), nodes("a", "b", "c"); !reflect.DeepEqual(path, want) {
+		t.Fatalf("got path %v, want %v", path, want)
+	}
+
+	// A shortcut is discovered.
+	g[[2]node{"b", "d"}] = 1
+	g[[2]node{"d", "c"}] = 1
+	p.AddVertex(node("d"))
+	if path, want := p.Plan(), nodes("a", "b", "d", "c"); !reflect.DeepEqual(path, want) {
+		t.Errorf("after AddVertex got path %v, want %v", path, want)
+	}
+
+	// And disappears again.
+	p.RemoveVertex(node("d"))
+	delete(g, [2]node{"b", "d"})
+	delete(g, [2]node{"d", "c"})
+	if path, want := p.Plan(), nodes("a", "b", "c"); !reflect.DeepEqual(path, want) {
+		t.Errorf("after RemoveVertex got path %v, want %v", path, want)
+	}
+	if cost := p.PathCost(); cost != 6 {
+		t.Errorf("after RemoveVertex PathCost() = %v, want 6", cost)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+// PlanVia plans a path from start to goal that passes through each of the
+// given waypoints (checkpoints) in order, by planning each leg separately and
+// joining them.
+//
+// Alongside the combined path it returns the index in the path at which each
+// waypoint, and finally the goal, is reached; so reached has one element more
+// than waypoints and path[reached[i]] equals waypoints[i]. This lets the
+// caller trigger events (e.g. "reached waypoint 2") as the agent progresses.
+//
+// If any leg has no path, nil is returned for both.
+func PlanVia(data Data, start State, waypoints []State, goal State) (path []State, reached []int) {
+	targets := append(append([]State(nil), waypoints...), goal)
+	path = []State{start}
+	from := start
+	for _, to := range targets {
+		leg := New(data, from, to).Plan()
+		if leg == nil {
+			return nil, nil
+		}
+		path = append(path, leg[1:]...)
+		reached = append(reached, len(path)-1)
+		from = to
+	}
+	return path, reached
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestPlanViaReached(t *testing.T) {
+	g, start, goal := parseGrid(`
+S.#....
+..#.##.
+.....#G
+`)
+	tests := []struct {
+		name      string
+		waypoints []dstarlite.State
+		reached   []int // nil if there is no path.
+	}{
+		{"no waypoints", nil, []int{12}},
+		{"one waypoint", []dstarlite.State{grid.Coord{X: 3, Y: 0}}, []int{7, 12}},
+		{"back and forth", []dstarlite.State{grid.Coord{X: 6, Y: 0}, grid.Coord{X: 0, Y: 2}}, []int{10, 18, 28}},
+		{"repeated waypoint", []dstarlite.State{start, start}, []int{0, 0, 12}},
+		{"blocked waypoint", []dstarlite.State{grid.Coord{X: 2, Y: 0}}, nil},
+	}
+	for _, tst := range tests {
+		path, reached := dstarlite.PlanVia(g, start, tst.waypoints, goal)
+		if tst.reached == nil {
+			if path != nil || reached != nil {
+				t.Errorf("%s: got path %v reaching %v, want nil", tst.name, path, reached)
+			}
+			continue
+		}
+		if len(reached) != len(tst.reached) {
+			t.Fatalf("%s: got indices %v, want %v", tst.name, reached, tst.reached)
+		}
+		for i, idx := range reached {
+			want := goal
+			if i < len(tst.waypoints) {
+				want = tst.waypoints[i].(grid.Coord)
+			}
+			if idx != tst.reached[i] || !path[idx].Equals(want) {
+				t.Errorf("%s: waypoint %d reached at index %d of %v, want %d", tst.name, i, idx, path, tst.reached[i])
+			}
+		}
+		if !path[0].Equals(start) || len(path) != reached[len(reached)-1]+1 {
+			t.Errorf("%s: got path %v, want it from %v to the goal", tst.name, path, start)
+		}
+	}
+}
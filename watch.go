@@ -0,0 +1,51 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite
+
+type watch[S comparable] struct {
+	check    func() bool
+	onChange func(path []S)
+	last     bool
+}
+
+// WatchCondition registers a condition over the map state to be watched by
+// Poll. Whenever the result of check differs from its result at the previous
+// Poll (or at registration), Poll replans and calls onChange with the new
+// path.
+//
+// This encapsulates the common poll-and-replan pattern: for instance check
+// may report whether a door is open, with onChange issuing new movement
+// commands. The caller remains responsible for flagging the underlying edge
+// cost changes (see FlagChanged).
+func (s *PlannerOf[S]) WatchCondition(check func() bool, onChange func(path []S)) {
+	s.watches = append(s.watches, &watch[S]{
+		check:    check,
+		onChange: onChange,
+		last:     check(),
+	})
+}
+
+// Poll evaluates every watched condition (see WatchCondition), and if any of
+// their results changed, replans once and calls the onChange function of each
+// changed condition with the new path. It returns whether a replan occurred,
+// and is intended to be called once per tick.
+func (s *PlannerOf[S]) Poll() bool {
+	var changed []*watch[S]
+	for _, w := range s.watches {
+		v := w.check()
+		if v != w.last {
+			w.last = v
+			changed = append(changed, w)
+		}
+	}
+	if len(changed) == 0 {
+		return false
+	}
+	path := s.Plan()
+	for _, w := range changed {
+		w.onChange(path)
+	}
+	return true
+}
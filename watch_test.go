@@ -0,0 +1,63 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dstarlite_test
+
+import (
+	"testing"
+
+	"azul3d.org/dstarlite.v1"
+	"azul3d.org/dstarlite.v1/grid"
+)
+
+func TestWatchCondition(t *testing.T) {
+	g, start, goal := parseGrid(`
+S...G
+.###.
+.....
+`)
+	door := grid.Coord{X: 2, Y: 0}
+	p := dstarlite.New(g, start, goal)
+	p.Plan()
+
+	closed := false
+	var paths [][]dstarlite.State
+	p.WatchCondition(func() bool { return closed }, func(path []dstarlite.State) {
+		paths = append(paths, path)
+	})
+
+	tests := []struct {
+		name   string
+		closed bool
+		replan bool
+		length int // Of the new path, if replanned.
+	}{
+		{"unchanged", false, false, 0},
+		{"door closed", true, true, 9},
+		{"still closed", true, false, 0},
+		{"door opened", false, true, 5},
+	}
+	for _, tst := range tests {
+		if tst.closed != closed {
+			closed = tst.closed
+			setBlocked(g, door, closed, p)
+		}
+		paths = nil
+		if replan := p.Poll(); replan != tst.replan {
+			t.Errorf("%s: Poll returned %v, want %v", tst.name, replan, tst.replan)
+		}
+		if !tst.replan {
+			if len(paths) != 0 {
+				t.Errorf("%s: onChange called %d times, want none", tst.name, len(paths))
+			}
+			continue
+		}
+		if len(paths) != 1 {
+			t.Fatalf("%s: onChange called %d times, want once", tst.name, len(paths))
+		}
+		if len(paths[0]) != tst.length {
+			t.Errorf("%s: new path %v has %d states, want %d", tst.name, paths[0], len(paths[0]), tst.length)
+		}
+	}
+}